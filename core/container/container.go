@@ -2,13 +2,22 @@ package container
 
 import (
 	"context"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/shared"
+	"fun_telegram/core/supplier/chart_supplier"
 	"fun_telegram/core/supplier/ds_supplier"
 	"fun_telegram/core/supplier/gigachat_supplier"
+	"fun_telegram/core/supplier/llm_supplier"
+	"fun_telegram/core/supplier/openai_supplier"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 
+	"fun_telegram/core/presentation/cli"
 	"fun_telegram/core/presentation/telegram"
 	"fun_telegram/core/service/analitics"
+	"fun_telegram/core/service/summarize_service"
 )
 
 type Container struct {
@@ -16,9 +25,22 @@ type Container struct {
 }
 
 func NewContainer(ctx context.Context) (Container, error) {
-	dsSupplier := ds_supplier.New()
+	drawer, err := newDrawer(ctx)
+	if err != nil {
+		return Container{}, errors.WithStack(err)
+	}
+
+	toxicityScorer, err := newToxicityScorer()
+	if err != nil {
+		return Container{}, errors.WithStack(err)
+	}
+
+	analiticsService, err := analitics.New(drawer, toxicityScorer)
+	if err != nil {
+		return Container{}, errors.WithStack(err)
+	}
 
-	analiticsService, err := analitics.New(dsSupplier)
+	dbRepository, err := db_repository.New(ctx, shared.AppSettings.DBPath)
 	if err != nil {
 		return Container{}, errors.WithStack(err)
 	}
@@ -28,14 +50,111 @@ func NewContainer(ctx context.Context) (Container, error) {
 		return Container{}, errors.WithStack(err)
 	}
 
-	gigachatSupplier, err := gigachat_supplier.NewSupplier(ctx)
+	llm, err := newLLM(ctx)
 	if err != nil {
 		return Container{}, errors.WithStack(err)
 	}
 
-	telegramPresentation := telegram.MustNewTelegramPresentation(protoClient, analiticsService, gigachatSupplier)
+	summarizeService, err := summarize_service.New(llm, shared.AppSettings.SummarizeTokenBudget)
+	if err != nil {
+		return Container{}, errors.WithStack(err)
+	}
+
+	telegramPresentation := telegram.MustNewTelegramPresentation(
+		protoClient,
+		analiticsService,
+		summarizeService,
+		dbRepository,
+	)
 
 	container := Container{telegramPresentation}
 
 	return container, nil
 }
+
+// NewCLIContainer
+// Builds container for subcommands of binary, telegram client is not created.
+func NewCLIContainer(ctx context.Context) (*cli.Presentation, error) {
+	toxicityScorer, err := newToxicityScorer()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	chartSupplier, err := chart_supplier.New()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	analiticsService, err := analitics.New(chartSupplier, toxicityScorer)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dbRepository, err := db_repository.New(ctx, shared.AppSettings.DBPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return cli.New(analiticsService, dbRepository), nil
+}
+
+func newDrawer(ctx context.Context) (analitics.Drawer, error) {
+	chartSupplier, err := chart_supplier.New()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch shared.AppSettings.ChartBackend {
+	case "native":
+		return chartSupplier, nil
+	case "ds":
+		dsSupplier := ds_supplier.New()
+
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		// ds is still tried on each chart, as it may become available later
+		err = dsSupplier.Ping(pingCtx)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("ds.supplier.unavailable")
+		}
+
+		return analitics.NewFallbackDrawer(dsSupplier, chartSupplier), nil
+	default:
+		return nil, errors.Errorf("unknown chart backend: %s", shared.AppSettings.ChartBackend)
+	}
+}
+
+func newLLM(ctx context.Context) (llm_supplier.LLM, error) {
+	switch shared.AppSettings.LLMProvider {
+	case "gigachat":
+		gigachatSupplier, err := gigachat_supplier.NewSupplier(ctx)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return gigachatSupplier, nil
+	case "openai":
+		return openai_supplier.New(
+			shared.AppSettings.OpenAI.BaseURL,
+			shared.AppSettings.OpenAI.APIKey,
+			shared.AppSettings.OpenAI.Model,
+			shared.AppSettings.OpenAI.Timeout,
+		), nil
+	default:
+		return nil, errors.Errorf("unknown llm provider: %s", shared.AppSettings.LLMProvider)
+	}
+}
+
+func newToxicityScorer() (analitics.ToxicityScorer, error) {
+	switch shared.AppSettings.ToxicityScorer {
+	case "regex":
+		return analitics.NewRegexToxicityScorer(), nil
+	case "lexicon":
+		return analitics.NewLexiconToxicityScorer(), nil
+	case "ds":
+		return analitics.NewDsToxicityScorer(ds_supplier.New()), nil
+	default:
+		return nil, errors.Errorf("unknown toxicity scorer: %s", shared.AppSettings.ToxicityScorer)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/supplier/tdesktop_supplier"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/teadove/teasutils/utils/closer_utils"
+)
+
+const importBatchSize = 1000
+
+// Import
+// Stores messages and their authors from result.json of Telegram Desktop export.
+func (r *Presentation) Import(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open export")
+	}
+	defer closer_utils.CloseOrLog(ctx, file)
+
+	var (
+		t0             = time.Now()
+		chat           tdesktop_supplier.Chat
+		count          int
+		oldest, newest message_service.Message
+	)
+
+	err = tdesktop_supplier.Read(file, importBatchSize, func(batch *tdesktop_supplier.Batch) error {
+		chat = batch.Chat
+		storage := r.analiticsService.NewStorage()
+
+		for _, message := range batch.Messages {
+			r.analiticsService.AppendMessage(storage, &message)
+
+			if oldest.TgID == 0 || message.TgID < oldest.TgID {
+				oldest = message
+			}
+
+			if message.TgID > newest.TgID {
+				newest = message
+			}
+		}
+
+		_, err := r.analiticsService.ScoreToxicity(ctx, storage.Messages)
+		if err != nil {
+			return errors.Wrap(err, "failed to score toxicity")
+		}
+
+		err = r.dbRepository.MessagesUpsert(ctx, storage.Messages)
+		if err != nil {
+			return errors.Wrap(err, "failed to save messages")
+		}
+
+		err = r.dbRepository.UsersInChatInsertMissing(ctx, batch.Users)
+		if err != nil {
+			return errors.Wrap(err, "failed to save users")
+		}
+
+		count += len(storage.Messages)
+
+		zerolog.Ctx(ctx).Info().
+			Int("count", count).
+			Time("last_date", storage.Messages[len(storage.Messages)-1].CreatedAt).
+			Msg("import.batch.saved")
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to import messages")
+	}
+
+	if count == 0 {
+		return errors.New("no messages imported")
+	}
+
+	state, err := r.dbRepository.SyncStateGet(ctx, chat.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync state")
+	}
+
+	state.ExtendByImported(&oldest, &newest)
+
+	if !state.IsEmpty() {
+		err = r.dbRepository.SyncStateUpsert(ctx, &state)
+		if err != nil {
+			return errors.Wrap(err, "failed to save sync state")
+		}
+	}
+
+	zerolog.Ctx(ctx).Info().
+		Int64("tg_chat_id", chat.ID).
+		Str("chat", chat.Name).
+		Int("count", count).
+		Str("elapsed", time.Since(t0).String()).
+		Msg("import.done")
+
+	return nil
+}
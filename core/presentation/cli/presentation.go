@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/analitics"
+
+	"github.com/pkg/errors"
+)
+
+// Presentation
+// Runs subcommands of binary, which work without telegram client.
+type Presentation struct {
+	analiticsService *analitics.Service
+	dbRepository     *db_repository.Repository
+}
+
+func New(analiticsService *analitics.Service, dbRepository *db_repository.Repository) *Presentation {
+	return &Presentation{analiticsService: analiticsService, dbRepository: dbRepository}
+}
+
+const usage = "usage: fun_telegram import <path to result.json>"
+
+// Run
+// Runs subcommand, args are passed without binary name.
+func (r *Presentation) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+
+		return r.Import(ctx, args[1])
+	default:
+		return errors.Errorf("unknown command: %s, %s", args[0], usage)
+	}
+}
+
+func (r *Presentation) Close() error {
+	err := r.dbRepository.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close db repository")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/celestix/gotgproto/types"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/gotd/td/telegram/peers"
+	"github.com/gotd/td/telegram/peers/members"
+	"github.com/pkg/errors"
+)
+
+// adminsCacheTTL is time, after which admins of chat are fetched again, so demoted admins lose rights quickly.
+const adminsCacheTTL = time.Minute
+
+type chatAdmins struct {
+	tgUserIDs mapset.Set[int64]
+	fetchedAt time.Time
+}
+
+// adminsCache
+// Keeps admins and creators of chats, fetched from telegram.
+type adminsCache struct {
+	mu    sync.Mutex
+	chats map[int64]chatAdmins
+}
+
+func newAdminsCache() *adminsCache {
+	return &adminsCache{chats: make(map[int64]chatAdmins)}
+}
+
+// get
+// Returns ids of admins and creator of chat, private chats have no admins.
+func (r *adminsCache) get(
+	ctx context.Context,
+	manager *peers.Manager,
+	effectiveChat types.EffectiveChat,
+) (mapset.Set[int64], error) {
+	tgChatID := effectiveChat.GetID()
+
+	r.mu.Lock()
+	cached, ok := r.chats[tgChatID]
+	r.mu.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < adminsCacheTTL {
+		return cached.tgUserIDs, nil
+	}
+
+	tgUserIDs := mapset.NewSet[int64]()
+	collect := func(member members.Member) error {
+		if member.Status() == members.Admin || member.Status() == members.Creator {
+			tgUserIDs.Add(member.User().ID())
+		}
+
+		return nil
+	}
+
+	switch t := effectiveChat.(type) {
+	case *types.Chat:
+		err := members.Chat(manager.Chat(t.Raw())).ForEach(ctx, collect)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to iterate over admins of chat")
+		}
+	case *types.Channel:
+		err := members.ChannelQuery{Channel: manager.Channel(t.Raw())}.Admins().ForEach(ctx, collect)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to iterate over admins of channel")
+		}
+	default:
+		return tgUserIDs, nil
+	}
+
+	r.mu.Lock()
+	r.chats[tgChatID] = chatAdmins{tgUserIDs: tgUserIDs, fetchedAt: time.Now()}
+	r.mu.Unlock()
+
+	return tgUserIDs, nil
+}
@@ -0,0 +1,143 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/service/analitics"
+	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/service/summarize_service"
+	"fun_telegram/core/shared"
+	"slices"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/celestix/gotgproto/storage"
+	"github.com/celestix/gotgproto/types"
+	"github.com/pkg/errors"
+)
+
+// askSourcesLimit is max amount of relevant messages sent to llm, least relevant are dropped by token budget
+const askSourcesLimit = 50
+
+// messageLink
+// Returns link to message, empty if chat is not a supergroup or channel, as links are not supported there.
+func messageLink(c *Context, tgChatID int64, tgID int) string {
+	var username string
+
+	if chat := c.update.EffectiveChat(); chat.GetID() == tgChatID {
+		channel, ok := chat.(*types.Channel)
+		if !ok {
+			return ""
+		}
+
+		username = channel.Username
+	} else {
+		peer := c.extCtx.PeerStorage.GetPeerById(tgChatID)
+		if storage.EntityType(peer.Type) != storage.TypeChannel {
+			return ""
+		}
+
+		username = peer.Username
+	}
+
+	if username != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", username, tgID)
+	}
+
+	return fmt.Sprintf("https://t.me/c/%d/%d", tgChatID, tgID)
+}
+
+// compileAskSources
+// Returns list of cited messages with their links.
+func compileAskSources(
+	c *Context,
+	storage *message_service.Storage,
+	relevant message_service.Messages,
+	cited []int,
+) string {
+	if len(cited) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+
+	text.WriteString("\n\nSources:\n")
+
+	for _, number := range cited {
+		message := relevant[number-1]
+
+		link := messageLink(c, message.TgChatID, message.TgID)
+		if link == "" {
+			link = fmt.Sprintf(
+				"%s, %s",
+				storage.UsersNameGetter.GetName(message.TgUserID),
+				message.CreatedAt.In(shared.TZTime).Format("2006-01-02 15:04"),
+			)
+		}
+
+		text.WriteString(fmt.Sprintf("[%d] %s\n", number, link))
+	}
+
+	return text.String()
+}
+
+// askCommand
+// Answers question about chat using stored messages, which share words with question.
+func (r *Presentation) askCommand(c *Context) error {
+	question := strings.TrimSpace(c.Text)
+	if question == "" {
+		return c.replyWithError(errors.New("pass question after command"))
+	}
+
+	input, err := statsGetArgs(c)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	storage, err := r.getChatStorage(c, &input)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chat storage")
+	}
+
+	// Command itself is not a source
+	commandTgID := c.update.EffectiveMessage.ID
+	messages := slices.DeleteFunc(storage.Messages, func(m message_service.Message) bool {
+		return m.TgID >= commandTgID
+	})
+
+	messages.ResolveReplies()
+
+	relevant := r.analiticsService.FindRelevantMessages(&analitics.FindRelevantInput{
+		Query:       question,
+		Limit:       askSourcesLimit,
+		Messages:    messages,
+		WordsConfig: storage.WordsConfig,
+	})
+	if len(relevant) == 0 {
+		return c.reply(ext.ReplyTextString("No messages related to question found"))
+	}
+
+	sources := make([]string, 0, len(relevant))
+	for idx, message := range relevant {
+		sources = append(sources, fmt.Sprintf("[%d] %s", idx+1, formatMessageForLLM(storage, &message)))
+	}
+
+	askInput := summarize_service.AskInput{Question: question, Sources: sources}
+
+	// In silent mode answer is sent to saved messages
+	placeholder, err := c.sendProgressMessage("💭")
+	if err != nil {
+		return errors.Wrap(err, "failed to send placeholder")
+	}
+
+	answer := newThrottledEditor(c, placeholder, summarizeEditInterval)
+	askInput.OnPartial = answer.update
+
+	resp, err := r.summarizeService.Ask(c.jobCtx(), &askInput)
+	if err != nil {
+		return errors.Wrap(err, "failed to ask")
+	}
+
+	cited := summarize_service.CitedSources(resp, len(relevant))
+
+	return r.sendAnswer(c, answer, resp+compileAskSources(c, storage, relevant, cited))
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"sync"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// capturedMessages
+// Holds the newest captured message of each chat since boot.
+// Captures are serialized, so sync state is not overwritten by concurrent handlers.
+type capturedMessages struct {
+	mu     sync.Mutex
+	newest map[int64]int
+}
+
+func newCapturedMessages() *capturedMessages {
+	return &capturedMessages{newest: make(map[int64]int)}
+}
+
+// captureMessage
+// Stores new messages of tracked chats, so stats for them are compiled without history upload.
+func (r *Presentation) captureMessage(ctx *ext.Context, update *ext.Update) error {
+	chatID := update.EffectiveChat().GetID()
+	if !r.captureChatIDs.Contains(chatID) || update.EffectiveMessage.IsService {
+		return nil
+	}
+
+	message, ok := newMessage(chatID, update.EffectiveMessage.Message)
+	if !ok {
+		return nil
+	}
+
+	storage := r.analiticsService.NewStorage()
+	r.analiticsService.AppendMessage(storage, &message)
+
+	_, err := r.analiticsService.ScoreToxicity(ctx, storage.Messages)
+	if err != nil {
+		return errors.Wrap(err, "failed to score toxicity")
+	}
+
+	r.captured.mu.Lock()
+	defer r.captured.mu.Unlock()
+
+	err = r.dbRepository.MessagesUpsert(ctx, storage.Messages)
+	if err != nil {
+		return errors.Wrap(err, "failed to save captured message")
+	}
+
+	state, err := r.dbRepository.SyncStateGet(ctx, chatID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get sync state")
+	}
+
+	prevCapturedTgID := r.captured.newest[chatID]
+	r.captured.newest[chatID] = max(prevCapturedTgID, message.TgID)
+
+	if state.ExtendByCaptured(message.TgID, prevCapturedTgID) {
+		err = r.dbRepository.SyncStateUpsert(ctx, &state)
+		if err != nil {
+			return errors.Wrap(err, "failed to save sync state")
+		}
+	}
+
+	zerolog.Ctx(ctx).Trace().Int("tg_id", message.TgID).Msg("message.captured")
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/service/message_service"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/pkg/errors"
+)
+
+// chatConfigCommand is name of command, which is run regardless of chat config, so bot can be enabled back.
+const chatConfigCommand = "chatconfig"
+
+// applyDefaultFlags
+// Sets default flags of command, which are not passed explicitly.
+func applyDefaultFlags(c *Context, command string, defaultFlags string, flags []optFlag) {
+	if defaultFlags == "" {
+		return
+	}
+
+	defaults := getOpt(fmt.Sprintf("!%s %s", command, defaultFlags), flags...)
+	if c.Ops == nil {
+		c.Ops = make(map[string]string, len(defaults.Ops))
+	}
+
+	for long, statement := range defaults.Ops {
+		if _, ok := c.Ops[long]; !ok {
+			c.Ops[long] = statement
+		}
+	}
+
+	c.Silent = c.Silent || defaults.Silent
+}
+
+// getConfigurableRoute
+// Returns command, which chat config can be applied to.
+func (r *Presentation) getConfigurableRoute(command string) (messageProcessor, error) {
+	route, ok := r.router[command]
+	if !ok || command == chatConfigCommand {
+		return messageProcessor{}, errors.Errorf("unknown command: %s", command)
+	}
+
+	return route, nil
+}
+
+// compileChatConfigMessage
+// Compiles human-readable description of chat config.
+func compileChatConfigMessage(config *message_service.ChatConfig) string {
+	var text strings.Builder
+
+	status := "enabled"
+	if config.Settings.Disabled {
+		status = "disabled"
+	}
+
+	replies := "on"
+	if config.Settings.RepliesDisabled {
+		replies = "off, commands of owner are run as silent"
+	}
+
+	admins := "not trusted, run only light commands"
+	if config.Settings.AdminsTrusted {
+		admins = "trusted"
+	}
+
+	text.WriteString(fmt.Sprintf("Bot: %s\nReplies: %s\nAdmins: %s\n", status, replies, admins))
+
+	if len(config.Rules) == 0 {
+		text.WriteString("Commands: all, no default flags\n")
+
+		return text.String()
+	}
+
+	text.WriteString("Commands:\n")
+
+	for _, rule := range config.Rules {
+		text.WriteString("/" + rule.Command)
+
+		if rule.Access != message_service.CommandAccessDefault {
+			text.WriteString(" - " + string(rule.Access))
+		}
+
+		if rule.DefaultFlags != "" {
+			text.WriteString(" - default flags: " + rule.DefaultFlags)
+		}
+
+		text.WriteString("\n")
+	}
+
+	return text.String()
+}
+
+// updateCommandRules
+// Applies change to rules of passed commands, rules without access and flags are deleted.
+func (r *Presentation) updateCommandRules(
+	c *Context,
+	config *message_service.ChatConfig,
+	commands []string,
+	update func(rule *message_service.CommandRule),
+) error {
+	rules := make(map[string]message_service.CommandRule, len(config.Rules))
+	for _, rule := range config.Rules {
+		rules[rule.Command] = rule
+	}
+
+	for _, command := range commands {
+		_, err := r.getConfigurableRoute(command)
+		if err != nil {
+			return c.replyWithError(err)
+		}
+	}
+
+	for _, command := range commands {
+		rule, ok := rules[command]
+		if !ok {
+			rule = message_service.CommandRule{TgChatID: config.Settings.TgChatID, Command: command}
+		}
+
+		update(&rule)
+
+		var err error
+		if rule.Access == message_service.CommandAccessDefault && rule.DefaultFlags == "" {
+			_, err = r.dbRepository.CommandRuleDelete(c.extCtx, rule.TgChatID, rule.Command)
+		} else {
+			err = r.dbRepository.CommandRuleUpsert(c.extCtx, &rule)
+		}
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		rules[command] = rule
+	}
+
+	config.Rules = config.Rules[:0]
+	for _, command := range slices.Sorted(maps.Keys(rules)) {
+		if rules[command].Access != message_service.CommandAccessDefault || rules[command].DefaultFlags != "" {
+			config.Rules = append(config.Rules, rules[command])
+		}
+	}
+
+	return c.reply(ext.ReplyTextString("Chat config updated\n\n" + compileChatConfigMessage(config)))
+}
+
+// chatConfigCommandHandler
+// Shows or changes config of bot in this chat: enable switch, replies, admins, allow and deny lists, default flags.
+// nolint: cyclop // switch of actions
+func (r *Presentation) chatConfigCommandHandler(c *Context) error {
+	action, text, _ := strings.Cut(c.Text, " ")
+	args := strings.Fields(text)
+
+	config, err := r.dbRepository.ChatConfigGet(c.extCtx, c.update.EffectiveChat().GetID())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch action {
+	case "", "show":
+		return c.reply(ext.ReplyTextString(compileChatConfigMessage(&config)))
+	case "enable", "disable":
+		config.Settings.Disabled = action == "disable"
+	case "replies":
+		if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+			return c.replyWithError(errors.New("pass on or off"))
+		}
+
+		config.Settings.RepliesDisabled = args[0] == "off"
+	case "admins":
+		if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+			return c.replyWithError(errors.New("pass on or off"))
+		}
+
+		config.Settings.AdminsTrusted = args[0] == "on"
+	case "allow", "deny", "reset":
+		if len(args) == 0 {
+			return c.replyWithError(errors.New("no commands passed"))
+		}
+
+		return r.updateCommandRules(c, &config, args, func(rule *message_service.CommandRule) {
+			switch action {
+			case "reset":
+				*rule = message_service.CommandRule{TgChatID: rule.TgChatID, Command: rule.Command}
+			default:
+				rule.Access = message_service.CommandAccess(action)
+			}
+		})
+	case "flags":
+		if len(args) == 0 {
+			return c.replyWithError(errors.New("pass command and its flags"))
+		}
+
+		route, err := r.getConfigurableRoute(args[0])
+		if err != nil {
+			return c.replyWithError(err)
+		}
+
+		defaultFlags := strings.Join(args[1:], " ")
+
+		parsed := getOpt(fmt.Sprintf("!%s %s", args[0], defaultFlags), route.flags...)
+		if parsed.Text != "" {
+			return c.replyWithError(errors.Errorf("unknown flags of %s: %s", args[0], parsed.Text))
+		}
+
+		return r.updateCommandRules(c, &config, args[:1], func(rule *message_service.CommandRule) {
+			rule.DefaultFlags = defaultFlags
+		})
+	default:
+		return c.replyWithError(errors.Errorf("unknown action: %s", action))
+	}
+
+	err = r.dbRepository.ChatSettingsUpsert(c.extCtx, &config.Settings)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.reply(ext.ReplyTextString("Chat config updated\n\n" + compileChatConfigMessage(&config)))
+}
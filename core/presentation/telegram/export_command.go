@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"bytes"
+	"fmt"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/pkg/errors"
+)
+
+var FlagExportFormat = optFlag{ // nolint: gochecknoglobals // FIXME
+	Long:        "format",
+	Short:       "f",
+	Description: "format of exported files: csv, jsonl or parquet",
+}
+
+// exportMIME maps export formats to mime types of sent documents.
+var exportMIME = map[message_service.ExportFormat]string{ // nolint: gochecknoglobals // FIXME
+	message_service.ExportFormatCSV:     "text/csv",
+	message_service.ExportFormatJSONL:   "application/jsonl",
+	message_service.ExportFormatParquet: "application/vnd.apache.parquet",
+}
+
+// exportCommand
+// Sends uploaded messages and members of chat as documents.
+func (r *Presentation) exportCommand(c *Context) error {
+	format, err := message_service.ParseExportFormat(c.Ops[FlagExportFormat.Long])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	input, err := statsGetArgs(c)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	storage, err := r.getChatStorage(c, &input)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chat storage")
+	}
+
+	chatID := c.update.EffectiveChat().GetID()
+
+	if _, ok := c.Ops[FlagStatsAnonymize.Long]; ok {
+		salt, err := r.anonymizeSalt(c)
+		if err != nil {
+			return errors.Wrap(err, "failed to get anonymize salt")
+		}
+
+		storage.UsersNameGetter = storage.GetAnonymizedNameGetter(chatID, salt)
+	}
+
+	var messagesBuf, membersBuf bytes.Buffer
+
+	err = message_service.Export(&messagesBuf, format, storage.ExportMessages())
+	if err != nil {
+		return errors.Wrap(err, "failed to export messages")
+	}
+
+	err = message_service.Export(&membersBuf, format, storage.ExportMembers())
+	if err != nil {
+		return errors.Wrap(err, "failed to export members")
+	}
+
+	fileUploader := uploader.NewUploader(c.extCtx.Raw)
+	documents := make([]message.MultiMediaOption, 0, 2)
+
+	for idx, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{name: fmt.Sprintf("messages_%d.%s", chatID, format), content: messagesBuf.Bytes()},
+		{name: fmt.Sprintf("members_%d.%s", chatID, format), content: membersBuf.Bytes()},
+	} {
+		uploaded, err := fileUploader.FromBytes(c.extCtx, file.name, file.content)
+		if err != nil {
+			return errors.Wrap(err, "failed to upload file")
+		}
+
+		var caption []styling.StyledTextOption
+		if idx == 1 {
+			caption = append(caption, styling.Plain(fmt.Sprintf(
+				"%s\n\nMessages: %d\nMembers: %d",
+				GetChatName(c.update.EffectiveChat()),
+				len(storage.Messages),
+				len(storage.Users),
+			)))
+		}
+
+		documents = append(documents, message.UploadedDocument(uploaded, caption...).
+			Filename(file.name).
+			MIME(exportMIME[format]).
+			ForceFile(true))
+	}
+
+	var requestBuilder *message.RequestBuilder
+	if c.Silent {
+		requestBuilder = c.extCtx.Sender.Self()
+	} else {
+		requestBuilder = c.extCtx.Sender.To(c.update.EffectiveChat().GetInputPeer())
+	}
+
+	_, err = requestBuilder.Album(c.extCtx, documents[0], documents[1:]...)
+	if err != nil {
+		return errors.Wrap(err, "failed to send documents")
+	}
+
+	return nil
+}
@@ -45,6 +45,7 @@ func (r *Presentation) updateMembers( // nolint: funlen // FIXME
 		username, _ := user.Username()
 
 		userInChat := message_service.UserInChat{
+			TgChatID:   effectiveChat.GetID(),
 			TgID:       user.ID(),
 			TgUsername: strings.ToLower(username),
 			TgName:     GetNameFromPeerUser(&user),
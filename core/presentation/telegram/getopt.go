@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"fun_telegram/core/service/message_service"
 	"strings"
 	"time"
 
@@ -18,10 +19,13 @@ type Context struct {
 	Silent    bool
 	Ops       map[string]string
 	StartedAt time.Time
+	// Role is role of sender of command in chat.
+	Role message_service.Role
 
 	extCtx       *ext.Context
 	update       *ext.Update
 	presentation *Presentation
+	job          *job
 }
 
 var FlagSilent = optFlag{Long: "silent", Short: "q"} // nolint: gochecknoglobals // FIXME
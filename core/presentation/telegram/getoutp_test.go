@@ -82,3 +82,18 @@ func TestUnit_GetArguments_LongDash_Ok(t *testing.T) {
 
 	assert.True(t, input.Silent)
 }
+
+func TestUnit_ApplyDefaultFlags_Ok(t *testing.T) {
+	t.Parallel()
+
+	input := getOpt("!stats -c=10", FlagUploadStatsCount, FlagUploadStatsDay)
+	applyDefaultFlags(&input, "stats", "-c=400 -d=30 --silent", []optFlag{FlagUploadStatsCount, FlagUploadStatsDay})
+
+	assert.Equal(t, map[string]string{"count": "10", "day": "30"}, input.Ops)
+	assert.True(t, input.Silent)
+
+	input = getOpt("!stats")
+	applyDefaultFlags(&input, "stats", "-d=30", []optFlag{FlagUploadStatsDay})
+
+	assert.Equal(t, map[string]string{"day": "30"}, input.Ops)
+}
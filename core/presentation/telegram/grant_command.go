@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"fun_telegram/core/service/message_service"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/celestix/gotgproto/types"
+	"github.com/pkg/errors"
+)
+
+var FlagGrantGlobal = optFlag{ // nolint: gochecknoglobals // FIXME
+	Long:        "global",
+	Short:       "g",
+	Description: "grant or revoke role in all chats",
+}
+
+// getRole
+// Returns role of user in chat, selfTgUserID is id of account, which bot runs on.
+func (r *Presentation) getRole(
+	ctx context.Context,
+	selfTgUserID int64,
+	tgUserID int64,
+	effectiveChat types.EffectiveChat,
+	settings *message_service.ChatSettings,
+) (message_service.Role, error) {
+	if tgUserID == selfTgUserID {
+		return message_service.RoleOwner, nil
+	}
+
+	granted, err := r.dbRepository.GrantExists(ctx, effectiveChat.GetID(), tgUserID)
+	if err != nil {
+		return message_service.RoleAnyone, errors.WithStack(err)
+	}
+
+	// Grant gives the highest role after owner, so admins are not fetched
+	if granted {
+		return message_service.RoleOf(false, true, settings), nil
+	}
+
+	admins, err := r.admins.get(ctx, r.telegramManager, effectiveChat)
+	if err != nil {
+		return message_service.RoleAnyone, errors.WithStack(err)
+	}
+
+	return message_service.RoleOf(admins.Contains(tgUserID), false, settings), nil
+}
+
+// requireRole
+// Returns error if sender of command has lower role, used for flags, which need more rights than command.
+func (r *Context) requireRole(role message_service.Role) error {
+	if r.Role < role {
+		return errors.Errorf("insufficient privilege: %s rights required", role)
+	}
+
+	return nil
+}
+
+// grantChatID
+// Returns chat, which grant is changed by command, or GlobalTgChatID for grant in all chats.
+func grantChatID(c *Context) int64 {
+	if _, ok := c.Ops[FlagGrantGlobal.Long]; ok {
+		return message_service.GlobalTgChatID
+	}
+
+	return c.update.EffectiveChat().GetID()
+}
+
+// grantCommand
+// Makes user trusted, so user can run commands, which require trusted role.
+func (r *Presentation) grantCommand(c *Context) error {
+	tgUserID, err := r.getTargetUser(c)
+	if err != nil {
+		return c.replyWithError(errors.Wrap(err, "failed to get target user"))
+	}
+
+	grant := message_service.Grant{TgChatID: grantChatID(c), TgUserID: tgUserID}
+
+	err = r.dbRepository.GrantUpsert(c.extCtx, &grant)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return c.reply(ext.ReplyTextString(fmt.Sprintf("Granted %s role to %d", message_service.RoleTrusted, tgUserID)))
+}
+
+// revokeCommand
+// Removes grant of user.
+func (r *Presentation) revokeCommand(c *Context) error {
+	tgUserID, err := r.getTargetUser(c)
+	if err != nil {
+		return c.replyWithError(errors.Wrap(err, "failed to get target user"))
+	}
+
+	deleted, err := r.dbRepository.GrantDelete(c.extCtx, &message_service.Grant{
+		TgChatID: grantChatID(c),
+		TgUserID: tgUserID,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !deleted {
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Err: %d has no grant", tgUserID)))
+	}
+
+	return c.reply(ext.ReplyTextString(fmt.Sprintf("Revoked %s role from %d", message_service.RoleTrusted, tgUserID)))
+}
+
+// grantsCommand
+// Lists trusted users of chat.
+func (r *Presentation) grantsCommand(c *Context) error {
+	tgChatID := c.update.EffectiveChat().GetID()
+
+	grants, err := r.dbRepository.GrantsGet(c.extCtx, tgChatID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	users, err := r.dbRepository.UsersInChatGet(c.extCtx, tgChatID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	nameGetter := users.GetNameGetter()
+
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("Users with %s role:\n", message_service.RoleTrusted))
+
+	for _, grant := range grants {
+		scope := "this chat"
+		if grant.TgChatID == message_service.GlobalTgChatID {
+			scope = "all chats"
+		}
+
+		text.WriteString(fmt.Sprintf(
+			"%s, id: %d, %s\n",
+			nameGetter.GetNameAndUsername(grant.TgUserID),
+			grant.TgUserID,
+			scope,
+		))
+	}
+
+	return c.reply(ext.ReplyTextString(text.String()))
+}
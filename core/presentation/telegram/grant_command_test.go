@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/message_service"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celestix/gotgproto/types"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func TestUnit_Telegram_GetRole_Ok(t *testing.T) {
+	t.Parallel()
+
+	const (
+		selfTgUserID    int64 = 1
+		tgChatID        int64 = 100
+		adminTgUserID   int64 = 10
+		grantedTgUserID int64 = 11
+		globalTgUserID  int64 = 12
+		anyoneTgUserID  int64 = 13
+	)
+
+	ctx := test_utils.GetLoggedContext()
+
+	repository, err := db_repository.New(ctx, filepath.Join(t.TempDir(), "fun.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, repository.Close()) })
+
+	require.NoError(t, repository.GrantUpsert(ctx, &message_service.Grant{TgChatID: tgChatID, TgUserID: grantedTgUserID}))
+	require.NoError(t, repository.GrantUpsert(ctx, &message_service.Grant{
+		TgChatID: message_service.GlobalTgChatID,
+		TgUserID: globalTgUserID,
+	}))
+
+	admins := newAdminsCache()
+	// Admins are fetched from telegram only if cache is expired
+	admins.chats[tgChatID] = chatAdmins{tgUserIDs: mapset.NewSet(adminTgUserID), fetchedAt: time.Now()}
+
+	presentation := Presentation{dbRepository: repository, admins: admins}
+	chat := &types.Chat{ID: tgChatID}
+
+	cases := []struct {
+		name          string
+		tgUserID      int64
+		adminsTrusted bool
+		expected      message_service.Role
+	}{
+		{name: "owner", tgUserID: selfTgUserID, expected: message_service.RoleOwner},
+		{name: "granted in chat", tgUserID: grantedTgUserID, expected: message_service.RoleTrusted},
+		{name: "granted in all chats", tgUserID: globalTgUserID, expected: message_service.RoleTrusted},
+		{name: "admin", tgUserID: adminTgUserID, expected: message_service.RoleAdmin},
+		{name: "trusted admin", tgUserID: adminTgUserID, adminsTrusted: true, expected: message_service.RoleTrusted},
+		{name: "anyone", tgUserID: anyoneTgUserID, adminsTrusted: true, expected: message_service.RoleAnyone},
+	}
+
+	for _, tc := range cases {
+		role, err := presentation.getRole(
+			ctx,
+			selfTgUserID,
+			tc.tgUserID,
+			chat,
+			&message_service.ChatSettings{TgChatID: tgChatID, AdminsTrusted: tc.adminsTrusted},
+		)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, role, tc.name)
+	}
+}
+
+func TestUnit_Telegram_RequireRole_Ok(t *testing.T) {
+	t.Parallel()
+
+	c := Context{Role: message_service.RoleTrusted}
+
+	require.NoError(t, c.requireRole(message_service.RoleAdmin))
+	require.NoError(t, c.requireRole(message_service.RoleTrusted))
+}
+
+func TestUnit_Telegram_RequireRole_Err(t *testing.T) {
+	t.Parallel()
+
+	c := Context{Role: message_service.RoleAdmin}
+
+	err := c.requireRole(message_service.RoleTrusted)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "trusted rights required")
+}
@@ -0,0 +1,465 @@
+package telegram
+
+import (
+	"context"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/message_service"
+	"time"
+
+	"fun_telegram/core/shared"
+
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/telegram/query/messages"
+	"github.com/gotd/td/tg"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type uploadStopReason int
+
+const (
+	uploadLimitReached uploadStopReason = iota
+	uploadStoredReached
+	uploadHistoryStartReached
+)
+
+// historyIterator
+// Iterates over messages of chat from newer to older ones.
+type historyIterator interface {
+	Next(ctx context.Context) bool
+	Value() messages.Elem
+	Err() error
+}
+
+// historyFetcher
+// Returns iterator over messages older than offsetID and offsetDate, zero values are ignored.
+type historyFetcher func(peer tg.InputPeerClass, offsetID int, offsetDate time.Time) historyIterator
+
+func (r *Presentation) fetchTelegramHistory(
+	peer tg.InputPeerClass,
+	offsetID int,
+	offsetDate time.Time,
+) historyIterator {
+	historyQuery := query.Messages(r.telegramAPI).GetHistory(peer)
+	historyQuery.BatchSize(iterHistoryBatchSize)
+	historyQuery.OffsetID(offsetID)
+
+	if !offsetDate.IsZero() {
+		historyQuery.OffsetDate(int(offsetDate.Unix()))
+	}
+
+	return historyQuery.Iter()
+}
+
+// historyUpload
+// Holds progress of one getChatStorage call, shared between sync phases.
+type historyUpload struct {
+	input *getChatStorageInput
+
+	tgChatID int64
+	peer     tg.InputPeerClass
+
+	bar progressMessage
+
+	startedAt time.Time
+	lastDate  time.Time
+	count     int
+
+	uploaded *message_service.Storage
+}
+
+func (r *historyUpload) limitReached() bool {
+	return (!r.lastDate.IsZero() && !r.lastDate.After(r.input.QueryTill)) ||
+		time.Since(r.startedAt) > r.input.MaxElapsed ||
+		r.count > r.input.MaxCount
+}
+
+// uploadHistory
+// Uploads messages older than offsetID and offsetDate till stopAtTgID, upload limits or start of chat history.
+// Zero offsetDate is ignored.
+// onFlush is called with the newest and the oldest fetched message after each batch is saved.
+// Messages not from users are not stored, but they are passed to onFlush, so sync state is moved over them.
+func (r *Presentation) uploadHistory( //nolint: funlen // FIXME
+	c *Context,
+	upload *historyUpload,
+	offsetID int,
+	offsetDate time.Time,
+	stopAtTgID int,
+	onFlush func(top int, oldest *message_service.Message) error,
+) (uploadStopReason, error) {
+	historyIter := r.fetchHistory(upload.peer, offsetID, offsetDate)
+
+	var (
+		top     int
+		oldest  message_service.Message
+		fetched bool
+	)
+
+	flush := func() error {
+		if !fetched {
+			return nil
+		}
+
+		err := r.flushUploaded(c, upload.uploaded)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		fetched = false
+
+		return onFlush(top, &oldest)
+	}
+
+	for {
+		zerolog.Ctx(c.extCtx).Trace().Int("offset", offsetID).Msg("new.iteration")
+
+		ok := historyIter.Next(c.jobCtx())
+		if !ok {
+			err := historyIter.Err()
+			if err != nil {
+				// Already uploaded messages are saved, so next upload continues from them.
+				flushErr := flush()
+				if flushErr != nil {
+					zerolog.Ctx(c.extCtx).Error().Stack().Err(flushErr).Msg("failed.to.flush.uploaded")
+				}
+
+				return 0, errors.WithStack(err)
+			}
+
+			zerolog.Ctx(c.extCtx).Info().Str("status", "all.messages.found").Send()
+
+			return uploadHistoryStartReached, flush()
+		}
+
+		elem := historyIter.Value()
+		offsetID = elem.Msg.GetID()
+
+		if offsetID <= stopAtTgID {
+			zerolog.Ctx(c.extCtx).Info().Str("status", "stored.messages.reached").Send()
+
+			return uploadStoredReached, flush()
+		}
+
+		if top == 0 {
+			top = offsetID
+		}
+
+		oldest = message_service.Message{
+			TgChatID:  upload.tgChatID,
+			TgID:      offsetID,
+			CreatedAt: time.Unix(int64(elem.Msg.GetDate()), 0).UTC(),
+		}
+		fetched = true
+
+		msg, ok := elem.Msg.(*tg.Message)
+		if !ok {
+			continue
+		}
+
+		upload.lastDate = time.Unix(int64(msg.Date), 0).In(shared.TZTime)
+		upload.count++
+
+		r.appendMessage(upload.tgChatID, upload.uploaded, elem)
+
+		if upload.count%iterHistoryBatchSize == 0 {
+			err := flush()
+			if err != nil {
+				return 0, errors.WithStack(err)
+			}
+
+			time.Sleep(time.Millisecond * 800)
+
+			go r.updateUploadStatsMessage(
+				c,
+				upload.bar,
+				upload.count,
+				offsetID,
+				upload.startedAt,
+				upload.lastDate,
+				upload.input.MaxCount,
+			)
+		}
+
+		if upload.limitReached() {
+			return uploadLimitReached, flush()
+		}
+	}
+}
+
+// syncHistory
+// Uploads messages of requested window, which are not stored yet.
+// Sync state is only changed if uploaded messages are adjacent to stored history.
+func (r *Presentation) syncHistory(
+	c *Context,
+	upload *historyUpload,
+	state *message_service.SyncState,
+	storedStats *db_repository.MessagesStats,
+) error {
+	input := upload.input
+
+	switch {
+	case !input.isWindowed() || windowAboveStored(input, state, storedStats):
+		err := r.syncNewest(c, upload, state)
+		if err != nil {
+			return errors.Wrap(err, "failed to sync newest messages")
+		}
+	case state.IsEmpty():
+		return r.syncWindow(c, upload, state)
+	case windowBelowStored(input, state):
+		if state.HistoryStartReached {
+			return nil
+		}
+
+		return r.syncWindow(c, upload, nil)
+	}
+
+	storedCount, err := r.dbRepository.MessagesCount(c.extCtx, input.messagesGetInput(state.TgChatID))
+	if err != nil {
+		return errors.Wrap(err, "failed to count stored messages")
+	}
+
+	err = r.syncOldest(c, upload, state, storedCount)
+	if err != nil {
+		return errors.Wrap(err, "failed to sync oldest messages")
+	}
+
+	return nil
+}
+
+// windowAboveStored
+// Returns true if requested window may contain messages newer than stored history.
+func windowAboveStored(
+	input *getChatStorageInput,
+	state *message_service.SyncState,
+	storedStats *db_repository.MessagesStats,
+) bool {
+	if state.IsEmpty() {
+		return false
+	}
+
+	return (input.OffsetID == 0 || input.OffsetID > state.NewestTgID) &&
+		(input.QueryUntil.IsZero() || input.QueryUntil.After(storedStats.Newest.CreatedAt))
+}
+
+// windowBelowStored
+// Returns true if requested window is entirely older than stored history.
+func windowBelowStored(input *getChatStorageInput, state *message_service.SyncState) bool {
+	return (input.OffsetID != 0 && input.OffsetID <= state.OldestTgID) ||
+		(!input.QueryUntil.IsZero() && !input.QueryUntil.After(state.OldestCreatedAt))
+}
+
+// syncWindow
+// Uploads messages of requested window from its top.
+// If state is passed, it is empty and uploaded messages become the stored history.
+func (r *Presentation) syncWindow(
+	c *Context,
+	upload *historyUpload,
+	state *message_service.SyncState,
+) error {
+	zerolog.Ctx(c.extCtx).Info().
+		Bool("initial", state != nil).
+		Int("offset", upload.input.OffsetID).
+		Time("until", upload.input.QueryUntil).
+		Msg("sync.window.begin")
+
+	reason, err := r.uploadHistory(
+		c,
+		upload,
+		upload.input.OffsetID,
+		upload.input.QueryUntil,
+		0,
+		func(top int, oldest *message_service.Message) error {
+			if state == nil {
+				return nil
+			}
+
+			if state.IsEmpty() {
+				state.NewestTgID = top
+			}
+
+			state.OldestTgID = oldest.TgID
+			state.OldestCreatedAt = oldest.CreatedAt
+
+			return r.saveSyncState(c, state)
+		},
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if state == nil || state.IsEmpty() {
+		return nil
+	}
+
+	if reason == uploadHistoryStartReached {
+		state.HistoryStartReached = true
+	}
+
+	return r.saveSyncState(c, state)
+}
+
+// syncNewest
+// Uploads messages newer than stored history, continuing unfinished upload if there is one.
+// If nothing is stored yet, uploaded messages become the stored history.
+func (r *Presentation) syncNewest(
+	c *Context,
+	upload *historyUpload,
+	state *message_service.SyncState,
+) error {
+	if state.HasGap() {
+		err := r.syncNewestPass(c, upload, state)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if state.HasGap() {
+			return nil
+		}
+	}
+
+	return r.syncNewestPass(c, upload, state)
+}
+
+func (r *Presentation) syncNewestPass(
+	c *Context,
+	upload *historyUpload,
+	state *message_service.SyncState,
+) error {
+	var (
+		initial  = state.IsEmpty()
+		resuming = state.HasGap()
+		offsetID int
+	)
+
+	if resuming {
+		offsetID = state.GapOffsetTgID
+	}
+
+	zerolog.Ctx(c.extCtx).Info().
+		Bool("initial", initial).
+		Bool("resuming", resuming).
+		Int("offset", offsetID).
+		Msg("sync.newest.begin")
+
+	reason, err := r.uploadHistory(
+		c,
+		upload,
+		offsetID,
+		time.Time{},
+		state.NewestTgID,
+		func(top int, oldest *message_service.Message) error {
+			switch {
+			case initial:
+				state.NewestTgID = top
+				state.OldestTgID = oldest.TgID
+				state.OldestCreatedAt = oldest.CreatedAt
+			case resuming:
+				state.GapOffsetTgID = oldest.TgID
+			default:
+				state.GapTopTgID = top
+				state.GapOffsetTgID = oldest.TgID
+			}
+
+			return r.saveSyncState(c, state)
+		},
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch reason {
+	case uploadHistoryStartReached:
+		if initial {
+			state.HistoryStartReached = true
+		}
+
+		state.CloseGap()
+	case uploadStoredReached:
+		state.CloseGap()
+	case uploadLimitReached:
+	}
+
+	return r.saveSyncState(c, state)
+}
+
+// syncOldest
+// Uploads messages older than stored history, if requested period is not stored yet.
+func (r *Presentation) syncOldest(
+	c *Context,
+	upload *historyUpload,
+	state *message_service.SyncState,
+	storedCount int,
+) error {
+	if state.IsEmpty() ||
+		state.HistoryStartReached ||
+		!state.OldestCreatedAt.After(upload.input.QueryTill) ||
+		storedCount >= upload.input.MaxCount ||
+		upload.limitReached() {
+		return nil
+	}
+
+	zerolog.Ctx(c.extCtx).Info().
+		Int("offset", state.OldestTgID).
+		Msg("sync.oldest.begin")
+
+	reason, err := r.uploadHistory(
+		c,
+		upload,
+		state.OldestTgID,
+		time.Time{},
+		0,
+		func(_ int, oldest *message_service.Message) error {
+			state.OldestTgID = oldest.TgID
+			state.OldestCreatedAt = oldest.CreatedAt
+
+			return r.saveSyncState(c, state)
+		},
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if reason == uploadHistoryStartReached {
+		state.HistoryStartReached = true
+	}
+
+	return r.saveSyncState(c, state)
+}
+
+// getSyncState
+// Returns stored sync state of chat.
+// Chats stored before sync states were introduced are treated as continuous history.
+func (r *Presentation) getSyncState(
+	c *Context,
+	chatID int64,
+) (message_service.SyncState, db_repository.MessagesStats, error) {
+	state, err := r.dbRepository.SyncStateGet(c.extCtx, chatID)
+	if err != nil {
+		return message_service.SyncState{}, db_repository.MessagesStats{}, errors.Wrap(err, "failed to get sync state")
+	}
+
+	storedStats, err := r.dbRepository.MessagesGetStats(c.extCtx, chatID)
+	if err != nil {
+		return message_service.SyncState{}, db_repository.MessagesStats{}, errors.Wrap(
+			err,
+			"failed to get stored messages stats",
+		)
+	}
+
+	if state.IsEmpty() && storedStats.Count > 0 {
+		state.NewestTgID = storedStats.Newest.TgID
+		state.OldestTgID = storedStats.Oldest.TgID
+		state.OldestCreatedAt = storedStats.Oldest.CreatedAt
+	}
+
+	return state, storedStats, nil
+}
+
+func (r *Presentation) saveSyncState(c *Context, state *message_service.SyncState) error {
+	err := r.dbRepository.SyncStateUpsert(c.extCtx, state)
+	if err != nil {
+		return errors.Wrap(err, "failed to save sync state")
+	}
+
+	return nil
+}
@@ -0,0 +1,349 @@
+package telegram
+
+import (
+	"context"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/analitics"
+	"fun_telegram/core/service/message_service"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/telegram/query/messages"
+	"github.com/gotd/td/tg"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+const historyTestTgChatID int64 = 1
+
+var historyTestStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) //nolint: gochecknoglobals // test data
+
+func historyTestDate(tgID int) time.Time {
+	return historyTestStart.Add(time.Hour * time.Duration(tgID))
+}
+
+func userMessage(tgID int) tg.NotEmptyMessage {
+	return &tg.Message{
+		ID:      tgID,
+		Date:    int(historyTestDate(tgID).Unix()),
+		FromID:  &tg.PeerUser{UserID: 10},
+		Message: "привет",
+	}
+}
+
+func channelPost(tgID int) tg.NotEmptyMessage {
+	return &tg.Message{
+		ID:      tgID,
+		Date:    int(historyTestDate(tgID).Unix()),
+		FromID:  &tg.PeerChannel{ChannelID: 20},
+		Message: "новости",
+	}
+}
+
+func serviceMessage(tgID int) tg.NotEmptyMessage {
+	return &tg.MessageService{ID: tgID, Date: int(historyTestDate(tgID).Unix())}
+}
+
+// stubHistory
+// Serves history of chat from memory, messages are sorted from newer to older ones.
+type stubHistory struct {
+	messages []tg.NotEmptyMessage
+	// failAfter is amount of messages, after which iteration fails, ignored if zero.
+	failAfter int
+}
+
+type stubHistoryIterator struct {
+	messages  []tg.NotEmptyMessage
+	failAfter int
+	idx       int
+	err       error
+}
+
+func (r *stubHistoryIterator) Next(_ context.Context) bool {
+	if r.failAfter != 0 && r.idx == r.failAfter {
+		r.err = errors.New("flood wait")
+
+		return false
+	}
+
+	if r.idx == len(r.messages) {
+		return false
+	}
+
+	r.idx++
+
+	return true
+}
+
+func (r *stubHistoryIterator) Value() messages.Elem {
+	return messages.Elem{Msg: r.messages[r.idx-1]}
+}
+
+func (r *stubHistoryIterator) Err() error {
+	return r.err
+}
+
+func (r *stubHistory) fetch(_ tg.InputPeerClass, offsetID int, offsetDate time.Time) historyIterator {
+	iter := stubHistoryIterator{failAfter: r.failAfter}
+
+	for _, msg := range r.messages {
+		if offsetID != 0 && msg.GetID() >= offsetID {
+			continue
+		}
+
+		if !offsetDate.IsZero() && int64(msg.GetDate()) >= offsetDate.Unix() {
+			continue
+		}
+
+		iter.messages = append(iter.messages, msg)
+	}
+
+	return &iter
+}
+
+func newStubHistory(messages ...tg.NotEmptyMessage) *stubHistory {
+	history := stubHistory{}
+	for idx := len(messages) - 1; idx >= 0; idx-- {
+		history.messages = append(history.messages, messages[idx])
+	}
+
+	return &history
+}
+
+func userMessages(from, to int) []tg.NotEmptyMessage {
+	result := make([]tg.NotEmptyMessage, 0, to-from+1)
+	for tgID := from; tgID <= to; tgID++ {
+		result = append(result, userMessage(tgID))
+	}
+
+	return result
+}
+
+type historySyncTest struct {
+	presentation *Presentation
+	c            *Context
+}
+
+func newHistorySyncTest(t *testing.T, history *stubHistory) *historySyncTest {
+	t.Helper()
+
+	ctx := test_utils.GetLoggedContext()
+
+	repository, err := db_repository.New(ctx, filepath.Join(t.TempDir(), "fun.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, repository.Close()) })
+
+	analiticsService, err := analitics.New(nil, analitics.NewRegexToxicityScorer())
+	require.NoError(t, err)
+
+	return &historySyncTest{
+		presentation: &Presentation{
+			dbRepository:     repository,
+			analiticsService: analiticsService,
+			fetchHistory:     history.fetch,
+		},
+		c: &Context{extCtx: &ext.Context{Context: ctx}},
+	}
+}
+
+func (r *historySyncTest) sync(
+	t *testing.T,
+	input getChatStorageInput,
+	state *message_service.SyncState,
+) error {
+	t.Helper()
+
+	if input.MaxCount == 0 {
+		input.MaxCount = 1000
+	}
+
+	input.MaxElapsed = time.Hour
+	input.QueryTill = historyTestStart.Add(-time.Hour)
+
+	storedStats, err := r.presentation.dbRepository.MessagesGetStats(r.c.extCtx, historyTestTgChatID)
+	require.NoError(t, err)
+
+	return r.presentation.syncHistory(r.c, &historyUpload{
+		input:     &input,
+		tgChatID:  historyTestTgChatID,
+		startedAt: time.Now(),
+		uploaded:  r.presentation.analiticsService.NewStorage(),
+	}, state, &storedStats)
+}
+
+func (r *historySyncTest) storedState(t *testing.T) message_service.SyncState {
+	t.Helper()
+
+	state, err := r.presentation.dbRepository.SyncStateGet(r.c.extCtx, historyTestTgChatID)
+	require.NoError(t, err)
+
+	return state
+}
+
+func (r *historySyncTest) storedCount(t *testing.T) int {
+	t.Helper()
+
+	count, err := r.presentation.dbRepository.MessagesCount(
+		r.c.extCtx,
+		&db_repository.MessagesGetInput{TgChatID: historyTestTgChatID},
+	)
+	require.NoError(t, err)
+
+	return count
+}
+
+func TestUnit_Telegram_SyncHistoryInitial_Ok(t *testing.T) {
+	t.Parallel()
+
+	test := newHistorySyncTest(t, newStubHistory(userMessages(1, 10)...))
+	state := message_service.SyncState{TgChatID: historyTestTgChatID}
+
+	require.NoError(t, test.sync(t, getChatStorageInput{}, &state))
+
+	stored := test.storedState(t)
+	assert.Equal(t, 10, stored.NewestTgID)
+	assert.Equal(t, 1, stored.OldestTgID)
+	assert.True(t, stored.HistoryStartReached)
+	assert.False(t, stored.HasGap())
+	assert.Equal(t, 10, test.storedCount(t))
+}
+
+func TestUnit_Telegram_SyncHistoryNotUserMessages_Ok(t *testing.T) {
+	t.Parallel()
+
+	history := newStubHistory(append(
+		userMessages(1, 5),
+		channelPost(6), serviceMessage(7), channelPost(8), channelPost(9), channelPost(10),
+	)...)
+	test := newHistorySyncTest(t, history)
+
+	state := message_service.SyncState{
+		TgChatID:            historyTestTgChatID,
+		NewestTgID:          5,
+		OldestTgID:          1,
+		OldestCreatedAt:     historyTestDate(1),
+		HistoryStartReached: true,
+	}
+
+	// Limit is reached on posts of channel, they are recorded as unfinished upload
+	require.NoError(t, test.sync(t, getChatStorageInput{MaxCount: 1}, &state))
+
+	stored := test.storedState(t)
+	assert.Equal(t, 5, stored.NewestTgID)
+	assert.Equal(t, 10, stored.GapTopTgID)
+	assert.Equal(t, 9, stored.GapOffsetTgID)
+
+	require.NoError(t, test.sync(t, getChatStorageInput{}, &state))
+
+	stored = test.storedState(t)
+	assert.Equal(t, 10, stored.NewestTgID)
+	assert.False(t, stored.HasGap())
+	assert.Zero(t, test.storedCount(t))
+}
+
+func TestUnit_Telegram_SyncHistoryResumeGap_Ok(t *testing.T) {
+	t.Parallel()
+
+	test := newHistorySyncTest(t, newStubHistory(userMessages(1, 12)...))
+
+	state := message_service.SyncState{
+		TgChatID:        historyTestTgChatID,
+		NewestTgID:      5,
+		OldestTgID:      1,
+		OldestCreatedAt: historyTestDate(1),
+		GapTopTgID:      10,
+		GapOffsetTgID:   8,
+	}
+
+	require.NoError(t, test.sync(t, getChatStorageInput{}, &state))
+
+	stored := test.storedState(t)
+	assert.Equal(t, 12, stored.NewestTgID)
+	assert.False(t, stored.HasGap())
+	// 7 and 6 are uploaded in gap, 12 and 11 above it, messages older than 1 are not in history
+	assert.Equal(t, 1, stored.OldestTgID)
+	assert.True(t, stored.HistoryStartReached)
+	assert.Equal(t, 4, test.storedCount(t))
+}
+
+func TestUnit_Telegram_SyncHistoryOldest_Ok(t *testing.T) {
+	t.Parallel()
+
+	test := newHistorySyncTest(t, newStubHistory(userMessages(1, 10)...))
+
+	state := message_service.SyncState{
+		TgChatID:        historyTestTgChatID,
+		NewestTgID:      10,
+		OldestTgID:      6,
+		OldestCreatedAt: historyTestDate(6),
+	}
+
+	require.NoError(t, test.sync(t, getChatStorageInput{}, &state))
+
+	stored := test.storedState(t)
+	assert.Equal(t, 10, stored.NewestTgID)
+	assert.Equal(t, 1, stored.OldestTgID)
+	assert.True(t, stored.OldestCreatedAt.Equal(historyTestDate(1)))
+	assert.True(t, stored.HistoryStartReached)
+	assert.Equal(t, 5, test.storedCount(t))
+}
+
+func TestUnit_Telegram_SyncHistoryWindowBelowStored_Ok(t *testing.T) {
+	t.Parallel()
+
+	test := newHistorySyncTest(t, newStubHistory(userMessages(1, 30)...))
+
+	state := message_service.SyncState{
+		TgChatID:        historyTestTgChatID,
+		NewestTgID:      30,
+		OldestTgID:      25,
+		OldestCreatedAt: historyTestDate(25),
+	}
+
+	require.NoError(t, test.sync(t, getChatStorageInput{OffsetID: 20}, &state))
+
+	// Uploaded window is not adjacent to stored history, so state is not changed
+	assert.Equal(t, 25, state.OldestTgID)
+	stored := test.storedState(t)
+	assert.True(t, stored.IsEmpty())
+	assert.Equal(t, 19, test.storedCount(t))
+}
+
+func TestUnit_Telegram_SyncHistoryFailed_Err(t *testing.T) {
+	t.Parallel()
+
+	history := newStubHistory(userMessages(1, 10)...)
+	history.failAfter = 3
+	test := newHistorySyncTest(t, history)
+
+	state := message_service.SyncState{TgChatID: historyTestTgChatID}
+
+	require.Error(t, test.sync(t, getChatStorageInput{}, &state))
+
+	// Messages fetched before error are saved, so next upload continues from them
+	stored := test.storedState(t)
+	assert.Equal(t, 10, stored.NewestTgID)
+	assert.Equal(t, 8, stored.OldestTgID)
+	assert.False(t, stored.HistoryStartReached)
+	assert.Equal(t, 3, test.storedCount(t))
+}
+
+func TestUnit_Telegram_WindowAboveAndBelowStored_Ok(t *testing.T) {
+	t.Parallel()
+
+	state := message_service.SyncState{NewestTgID: 100, OldestTgID: 50, OldestCreatedAt: historyTestDate(50)}
+	storedStats := db_repository.MessagesStats{Newest: message_service.Message{CreatedAt: historyTestDate(100)}}
+
+	assert.True(t, windowAboveStored(&getChatStorageInput{}, &state, &storedStats))
+	assert.True(t, windowAboveStored(&getChatStorageInput{OffsetID: 120}, &state, &storedStats))
+	assert.False(t, windowAboveStored(&getChatStorageInput{OffsetID: 80}, &state, &storedStats))
+	assert.False(t, windowAboveStored(&getChatStorageInput{QueryUntil: historyTestDate(90)}, &state, &storedStats))
+
+	assert.True(t, windowBelowStored(&getChatStorageInput{OffsetID: 50}, &state))
+	assert.True(t, windowBelowStored(&getChatStorageInput{QueryUntil: historyTestDate(40)}, &state))
+	assert.False(t, windowBelowStored(&getChatStorageInput{OffsetID: 80}, &state))
+}
@@ -0,0 +1,155 @@
+package telegram
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// job
+// Running command, which can be cancelled with !cancel.
+type job struct {
+	id        int
+	command   string
+	tgChatID  int64
+	chatName  string
+	tgUserID  int64
+	startedAt time.Time
+
+	ctx    context.Context // nolint: containedctx // context of command is cancelled by other command
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	progress string
+}
+
+func (r *job) setProgress(progress string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.progress = progress
+}
+
+func (r *job) getProgress() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.progress
+}
+
+func (r *job) cancelled() bool {
+	return r.ctx.Err() != nil
+}
+
+// jobRegistry
+// Tracks running commands, ids are increasing and are not reused until restart.
+type jobRegistry struct {
+	mu     sync.Mutex
+	lastID int
+	jobs   map[int]*job
+	// stopping is set on shutdown, new jobs are not started after it
+	stopping bool
+
+	running sync.WaitGroup
+}
+
+var ErrShuttingDown = errors.New("bot is shutting down")
+
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[int]*job)}
+}
+
+// start
+// Registers job with context, derived from ctx, job must be finished by caller.
+// Returns ErrShuttingDown if registry is stopped.
+func (r *jobRegistry) start(ctx context.Context, newJob *job) (*job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopping {
+		return nil, errors.WithStack(ErrShuttingDown)
+	}
+
+	r.lastID++
+
+	newJob.id = r.lastID
+	newJob.ctx, newJob.cancel = context.WithCancel(ctx)
+	r.jobs[newJob.id] = newJob
+	r.running.Add(1)
+
+	return newJob, nil
+}
+
+// stop
+// Forbids start of new jobs, so running ones can be waited.
+func (r *jobRegistry) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopping = true
+}
+
+func (r *jobRegistry) finish(finished *job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	finished.cancel()
+	delete(r.jobs, finished.id)
+	r.running.Done()
+}
+
+// cancelAll
+// Cancels all running jobs, they are still to be finished by callers.
+func (r *jobRegistry) cancelAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, running := range r.jobs {
+		running.cancel()
+	}
+}
+
+// wait
+// Waits till all jobs are finished or ctx is done, registry must be stopped before.
+func (r *jobRegistry) wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+func (r *jobRegistry) get(id int) (*job, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found, ok := r.jobs[id]
+
+	return found, ok
+}
+
+// list
+// Returns running jobs from oldest to newest.
+func (r *jobRegistry) list() []*job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := make([]*job, 0, len(r.jobs))
+	for _, id := range slices.Sorted(maps.Keys(r.jobs)) {
+		jobs = append(jobs, r.jobs[id])
+	}
+
+	return jobs
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/service/message_service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/pkg/errors"
+)
+
+// jobsCommand
+// Lists running commands with their progress, only owner sees commands of other chats.
+func (r *Presentation) jobsCommand(c *Context) error {
+	tgChatID := c.update.EffectiveChat().GetID()
+
+	var text strings.Builder
+
+	for _, running := range r.jobs.list() {
+		if running == c.job || (c.Role < message_service.RoleOwner && running.tgChatID != tgChatID) {
+			continue
+		}
+
+		text.WriteString(fmt.Sprintf(
+			"#%d /%s in %s, running for %s\n",
+			running.id,
+			running.command,
+			running.chatName,
+			time.Since(running.startedAt).Round(time.Second),
+		))
+
+		if progress := running.getProgress(); progress != "" {
+			text.WriteString(progress + "\n")
+		}
+
+		text.WriteString("\n")
+	}
+
+	if text.Len() == 0 {
+		return c.reply(ext.ReplyTextString("No running commands"))
+	}
+
+	text.WriteString("Cancel with: !cancel <id>")
+
+	return c.reply(ext.ReplyTextString(text.String()))
+}
+
+// cancelCommand
+// Cancels running command, owner can cancel any command, others only own ones.
+func (r *Presentation) cancelCommand(c *Context) error {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(c.Text), "#"))
+	if err != nil {
+		return c.replyWithError(errors.New("pass id of command from !jobs"))
+	}
+
+	running, ok := r.jobs.get(id)
+	if !ok || (c.Role < message_service.RoleOwner && running.tgUserID != c.update.EffectiveUser().GetID()) {
+		return c.replyWithError(errors.Errorf("command #%d is not found", id))
+	}
+
+	running.cancel()
+
+	return c.reply(ext.ReplyTextString(fmt.Sprintf("Command #%d /%s is cancelled", running.id, running.command)))
+}
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_JobRegistry_StartFinish_Ok(t *testing.T) {
+	t.Parallel()
+
+	registry := newJobRegistry()
+
+	first, err := registry.start(context.Background(), &job{command: "stats"})
+	require.NoError(t, err)
+
+	second, err := registry.start(context.Background(), &job{command: "summarize"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, first.id)
+	assert.Equal(t, 2, second.id)
+
+	second.setProgress("⚙️ Summarizing messages")
+
+	jobs := registry.list()
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "stats", jobs[0].command)
+	assert.Equal(t, "⚙️ Summarizing messages", jobs[1].getProgress())
+
+	found, ok := registry.get(1)
+	require.True(t, ok)
+	found.cancel()
+	assert.True(t, first.cancelled())
+	assert.False(t, second.cancelled())
+
+	registry.finish(first)
+	registry.finish(second)
+	assert.Empty(t, registry.list())
+	assert.True(t, second.cancelled())
+
+	_, ok = registry.get(1)
+	assert.False(t, ok)
+}
+
+func TestUnit_JobRegistry_Wait_Ok(t *testing.T) {
+	t.Parallel()
+
+	registry := newJobRegistry()
+	running, err := registry.start(context.Background(), &job{command: "stats"})
+	require.NoError(t, err)
+
+	registry.stop()
+
+	_, err = registry.start(context.Background(), &job{command: "summarize"})
+	require.ErrorIs(t, err, ErrShuttingDown)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, registry.wait(ctx), context.DeadlineExceeded)
+
+	go func() {
+		<-running.ctx.Done()
+		registry.finish(running)
+	}()
+
+	registry.cancelAll()
+
+	require.NoError(t, registry.wait(context.Background()))
+}
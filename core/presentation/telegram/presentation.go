@@ -2,13 +2,17 @@ package telegram
 
 import (
 	"context"
-	"fun_telegram/core/supplier/gigachat_supplier"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/message_service"
+	"sync"
 	"time"
 
 	"github.com/celestix/gotgproto/dispatcher/handlers/filters"
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/glebarez/sqlite"
 
 	"fun_telegram/core/service/analitics"
+	"fun_telegram/core/service/summarize_service"
 	"fun_telegram/core/shared"
 
 	"github.com/celestix/gotgproto"
@@ -31,10 +35,21 @@ type Presentation struct {
 	telegramManager *peers.Manager
 	protoClient     *gotgproto.Client
 
-	router map[string]messageProcessor
+	router         map[string]messageProcessor
+	fetchHistory   historyFetcher
+	captureChatIDs mapset.Set[int64]
+	captured       *capturedMessages
+	jobs           *jobRegistry
+	admins         *adminsCache
+	uploadQueue    *uploadQueue
+
+	// restart is closed by !restart
+	restart     chan struct{}
+	restartOnce sync.Once
 
 	analiticsService *analitics.Service
-	gigachatSupplier *gigachat_supplier.Supplier
+	summarizeService *summarize_service.Service
+	dbRepository     *db_repository.Repository
 }
 
 func NewProtoClient(ctx context.Context) (*gotgproto.Client, error) {
@@ -86,7 +101,8 @@ func NewProtoClient(ctx context.Context) (*gotgproto.Client, error) {
 func MustNewTelegramPresentation(
 	protoClient *gotgproto.Client,
 	analiticsService *analitics.Service,
-	gigachatSupplier *gigachat_supplier.Supplier,
+	summarizeService *summarize_service.Service,
+	dbRepository *db_repository.Repository,
 ) *Presentation {
 	api := protoClient.API()
 
@@ -95,15 +111,31 @@ func MustNewTelegramPresentation(
 		telegramAPI:      api,
 		telegramManager:  peers.Options{}.Build(api),
 		analiticsService: analiticsService,
-		gigachatSupplier: gigachatSupplier,
+		summarizeService: summarizeService,
+		dbRepository:     dbRepository,
+		captureChatIDs:   mapset.NewSet(shared.AppSettings.Telegram.CaptureChatIDs...),
+		captured:         newCapturedMessages(),
+		jobs:             newJobRegistry(),
+		admins:           newAdminsCache(),
+		uploadQueue:      newUploadQueue(shared.AppSettings.Telegram.UploadConcurrency),
+		restart:          make(chan struct{}),
 	}
 
+	presentation.fetchHistory = presentation.fetchTelegramHistory
+
 	protoClient.Dispatcher.AddHandler(
 		handlers.Message{
 			Callback: presentation.injectContext,
 			Outgoing: true,
 		},
 	)
+	protoClient.Dispatcher.AddHandler(
+		handlers.Message{
+			Callback:      presentation.captureMessage,
+			UpdateFilters: filterNonNewMessagesNotFromUser,
+			Outgoing:      true,
+		},
+	)
 	protoClient.Dispatcher.AddHandler(
 		handlers.Message{
 			Callback:      presentation.deleteOut,
@@ -126,6 +158,7 @@ func MustNewTelegramPresentation(
 			executor:    presentation.helpCommandHandler,
 			description: "get this message",
 			flags:       []optFlag{},
+			role:        message_service.RoleAdmin,
 		},
 		"stats": {
 			executor:    presentation.statsCommand,
@@ -134,18 +167,132 @@ func MustNewTelegramPresentation(
 				FlagUploadStatsCount,
 				FlagUploadStatsDay,
 				FlagUploadStatsOffset,
+				FlagUploadStatsSince,
+				FlagUploadStatsUntil,
+				FlagStatsAnonymize,
+				FlagStatsAnonymizeMapping,
+			},
+			example: "-c=400000 --since=2023-12-01 --until=2023-12-31 --silent",
+			role:    message_service.RoleTrusted,
+		},
+		"whois": {
+			executor:    presentation.whoisCommand,
+			description: "compiles stats of user, reply to message of user or pass @username",
+			flags: []optFlag{
+				FlagUploadStatsCount,
+				FlagUploadStatsDay,
+			},
+			example: "@username -d=30",
+			role:    message_service.RoleTrusted,
+		},
+		"export": {
+			executor:    presentation.exportCommand,
+			description: "exports messages and members of this chat as files",
+			flags: []optFlag{
+				FlagExportFormat,
+				FlagUploadStatsCount,
+				FlagUploadStatsDay,
+				FlagUploadStatsOffset,
+				FlagUploadStatsSince,
+				FlagUploadStatsUntil,
 				FlagStatsAnonymize,
 			},
-			example: "-c=400000 -d=365 -o=0 --silent",
+			example: "--format=parquet -d=30 --anonymize",
+			role:    message_service.RoleTrusted,
+		},
+		"stopwords": {
+			executor:    presentation.stopWordsCommand,
+			description: "adds, removes or lists stop words of word analytics",
+			flags:       []optFlag{FlagWordsGlobal},
+			example:     "add кот собака --global",
+			role:        message_service.RoleTrusted,
+		},
+		"lemmas": {
+			executor:    presentation.lemmasCommand,
+			description: "sets, resets or lists replacements of words in word analytics",
+			flags:       []optFlag{FlagWordsGlobal},
+			example:     "set котик кот",
+			role:        message_service.RoleTrusted,
 		},
 		"summarize": {
 			executor:    presentation.summarizeCommand,
-			description: "summarize last messages",
-			flags:       []optFlag{},
+			description: "summarize last messages, reply to message to summarize its reply thread",
+			flags: []optFlag{
+				FlagUploadStatsCount,
+				FlagUploadStatsDay,
+				FlagUploadStatsSince,
+				FlagUploadStatsUntil,
+				FlagSummarizeMine,
+			},
+			example: "--since=2024-01-01 --until=2024-01-07",
+			role:    message_service.RoleTrusted,
+		},
+		"ask": {
+			executor:    presentation.askCommand,
+			description: "answers question about this chat with links to messages it is based on",
+			flags: []optFlag{
+				FlagUploadStatsCount,
+				FlagUploadStatsDay,
+				FlagUploadStatsSince,
+				FlagUploadStatsUntil,
+			},
+			example: "what did we decide about the release date?",
+			role:    message_service.RoleTrusted,
+		},
+		"search": {
+			executor:    presentation.searchCommand,
+			description: "searches stored messages of chat by words in any form",
+			flags: []optFlag{
+				FlagSearchFrom,
+				FlagSearchChat,
+				FlagSearchPage,
+				FlagUploadStatsSince,
+				FlagUploadStatsUntil,
+			},
+			example: "релиз --from=@username --since=2024-01-01 --page=2",
+			role:    message_service.RoleTrusted,
+		},
+		"grant": {
+			executor:    presentation.grantCommand,
+			description: "grants trusted role to user, reply to message of user or pass @username",
+			flags:       []optFlag{FlagGrantGlobal},
+			example:     "@username --global",
+			role:        message_service.RoleOwner,
+		},
+		"revoke": {
+			executor:    presentation.revokeCommand,
+			description: "revokes trusted role from user, reply to message of user or pass @username",
+			flags:       []optFlag{FlagGrantGlobal},
+			example:     "@username",
+			role:        message_service.RoleOwner,
+		},
+		"grants": {
+			executor:    presentation.grantsCommand,
+			description: "lists users with trusted role in this chat",
+			role:        message_service.RoleTrusted,
+		},
+		"jobs": {
+			executor:    presentation.jobsCommand,
+			description: "lists running commands with their progress",
+			role:        message_service.RoleTrusted,
+		},
+		"cancel": {
+			executor:    presentation.cancelCommand,
+			description: "cancels running command by id from jobs, uploaded messages are kept",
+			example:     "3",
+			role:        message_service.RoleTrusted,
+		},
+		chatConfigCommand: {
+			executor: presentation.chatConfigCommandHandler,
+			description: "shows or changes config of bot in this chat: " +
+				"enable, disable, replies on/off, admins on/off, allow, deny, reset, flags",
+			example: "flags summarize -c=500",
+			role:    message_service.RoleOwner,
 		},
 		"restart": {
 			executor:    presentation.restartCommandHandler,
 			description: "restarts bot",
+			role:        message_service.RoleOwner,
 		},
 	}
 
@@ -185,6 +332,8 @@ func (r *Presentation) panicHandler(
 		Msg("panic.while.processing.update")
 }
 
+// Run
+// Runs bot till ctx is done, client fails or restart is requested, then shuts bot down gracefully.
 func (r *Presentation) Run(ctx context.Context) error {
 	user := r.protoClient.Self
 	zerolog.Ctx(ctx).
@@ -192,9 +341,74 @@ func (r *Presentation) Run(ctx context.Context) error {
 		Str("username", user.Username).
 		Msg("starting.bot")
 
-	err := r.protoClient.Idle()
+	extCtx := r.protoClient.CreateContext()
+	extCtx.Context = zerolog.Ctx(ctx).WithContext(extCtx.Context)
+	r.reportRestart(extCtx)
+
+	idleErr := make(chan error, 1)
+
+	go func() {
+		idleErr <- r.protoClient.Idle()
+	}()
+
+	select {
+	case err := <-idleErr:
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		zerolog.Ctx(ctx).Warn().Msg("shutdown.signal.received")
+	case <-r.restart:
+		zerolog.Ctx(ctx).Warn().Msg("shutdown.restart.requested")
+	}
+
+	r.shutdown(ctx)
+
+	return nil
+}
+
+// shutdown
+// Waits for running commands, cancels them after timeout and stops client.
+func (r *Presentation) shutdown(ctx context.Context) {
+	// Grace period for cancelled commands to save uploaded messages and reply
+	const cancelTimeout = 10 * time.Second
+
+	r.jobs.stop()
+
+	// ctx is already done on signal, but logger is still needed
+	ctx = context.WithoutCancel(ctx)
+
+	drainCtx, cancel := context.WithTimeout(ctx, shared.AppSettings.ShutdownTimeout)
+	defer cancel()
+
+	err := r.jobs.wait(drainCtx)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Msg("shutdown.timeout.cancelling.commands")
+
+		r.jobs.cancelAll()
+
+		cancelCtx, cancel := context.WithTimeout(ctx, cancelTimeout)
+		defer cancel()
+
+		err = r.jobs.wait(cancelCtx)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Msg("shutdown.commands.not.finished")
+		}
+	}
+
+	r.protoClient.Stop()
+
+	zerolog.Ctx(ctx).Info().Msg("bot.stopped")
+}
+
+// Close
+// Closes storage, must be called after Run returns.
+func (r *Presentation) Close() error {
+	err := r.dbRepository.Close()
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.Wrap(err, "failed to close db repository")
 	}
 
 	return nil
@@ -1,22 +1,71 @@
 package telegram
 
 import (
+	"fmt"
+	"fun_telegram/core/shared"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/celestix/gotgproto/ext"
 	"github.com/gotd/td/tg"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
+// restartMarkerPath
+// Returns path of file, which keeps time of requested restart, so bot reports it after boot.
+func restartMarkerPath() string {
+	return filepath.Join(filepath.Dir(shared.AppSettings.DBPath), "restart")
+}
+
+// restartCommandHandler
+// Requests graceful shutdown, bot is started again by supervisor, e.g. docker with restart policy.
 func (r *Presentation) restartCommandHandler(c *Context) error {
-	_, err := c.extCtx.SendMessage(c.extCtx.Self.ID, &tg.MessagesSendMessageRequest{Message: "Restarting..."})
+	err := os.WriteFile(restartMarkerPath(), []byte(time.Now().UTC().Format(time.RFC3339)), 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to write restart marker")
+	}
+
+	_, err = c.extCtx.SendMessage(c.extCtx.Self.ID, &tg.MessagesSendMessageRequest{Message: "Restarting..."})
 	if err != nil {
 		return errors.Wrap(err, "failed to send message")
 	}
 
 	zerolog.Ctx(c.extCtx).Warn().Msg("reload.begin")
 
-	os.Exit(0)
+	// Shutdown waits for running commands, so this one must not block
+	r.restartOnce.Do(func() { close(r.restart) })
 
 	return nil
 }
+
+// reportRestart
+// Sends message to saved messages if bot was restarted by command.
+func (r *Presentation) reportRestart(ctx *ext.Context) {
+	content, err := os.ReadFile(restartMarkerPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			zerolog.Ctx(ctx).Error().Stack().Err(err).Msg("failed.to.read.restart.marker")
+		}
+
+		return
+	}
+
+	err = os.Remove(restartMarkerPath())
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Stack().Err(err).Msg("failed.to.remove.restart.marker")
+	}
+
+	text := "Restarted"
+
+	requestedAt, err := time.Parse(time.RFC3339, string(content))
+	if err == nil {
+		text = fmt.Sprintf("Restarted, downtime: %s", time.Since(requestedAt).Round(time.Second))
+	}
+
+	_, err = ctx.SendMessage(ctx.Self.ID, &tg.MessagesSendMessageRequest{Message: text})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Stack().Err(err).Msg("failed.to.report.restart")
+	}
+}
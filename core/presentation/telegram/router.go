@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"fmt"
+	"fun_telegram/core/service/message_service"
 	"strings"
 	"time"
 
@@ -16,6 +17,8 @@ type messageProcessor struct {
 	description string
 	flags       []optFlag
 	example     string
+	// role is min role of sender, which is required to run command.
+	role message_service.Role
 }
 
 // route
@@ -41,13 +44,53 @@ func (r *Presentation) route(ctx *ext.Context, update *ext.Update) error {
 		Logger().
 		WithContext(ctx.Context)
 
+	config, err := r.dbRepository.ChatConfigGet(ctx, update.EffectiveChat().GetID())
+	if err != nil {
+		return errors.Wrap(err, "failed to get chat config")
+	}
+
+	configurable := command != chatConfigCommand
+	if configurable && !config.CommandAllowed(command) {
+		zerolog.Ctx(ctx.Context).Debug().Msg("command.is.disabled.in.chat")
+
+		return nil
+	}
+
 	c := getOpt(text, route.flags...)
 	c.extCtx = ctx
 	c.update = update
 	c.presentation = r
 
-	if update.EffectiveUser().GetID() != ctx.Self.ID {
-		_, err := ctx.Reply(update, ext.ReplyTextString("Err: insufficient privilege: owner rights required"), nil)
+	if configurable {
+		applyDefaultFlags(&c, command, config.DefaultFlags(command), route.flags)
+	}
+
+	role, err := r.getRole(
+		ctx,
+		ctx.Self.ID,
+		update.EffectiveUser().GetID(),
+		update.EffectiveChat(),
+		&config.Settings,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get role")
+	}
+
+	if configurable && config.Settings.RepliesDisabled {
+		// Only owner can get results in saved messages
+		if role < message_service.RoleOwner {
+			return nil
+		}
+
+		c.Silent = true
+	}
+
+	if role < route.role {
+		_, err = ctx.Reply(
+			update,
+			ext.ReplyTextString(fmt.Sprintf("Err: insufficient privilege: %s rights required", route.role)),
+			nil,
+		)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -55,14 +98,32 @@ func (r *Presentation) route(ctx *ext.Context, update *ext.Update) error {
 		return nil
 	}
 
+	c.Role = role
+	// Silent results are sent to saved messages of owner, so others can not use it
+	c.Silent = c.Silent && role == message_service.RoleOwner
+
 	c.StartedAt = time.Now().UTC()
+	c.job, err = r.jobs.start(ctx.Context, &job{
+		command:   command,
+		tgChatID:  update.EffectiveChat().GetID(),
+		chatName:  GetChatName(update.EffectiveChat()),
+		tgUserID:  update.EffectiveUser().GetID(),
+		startedAt: c.StartedAt,
+	})
+	if err != nil {
+		zerolog.Ctx(ctx.Context).Warn().Err(err).Msg("command.skipped.on.shutdown")
+
+		return nil
+	}
+
+	defer r.jobs.finish(c.job)
 
 	zerolog.Ctx(ctx.Context).
 		Debug().
 		Str("command", firstWord).
 		Msg("executing.command.begin")
 
-	err := route.executor(&c)
+	err = route.executor(&c)
 	elapsed := time.Now().UTC().Sub(c.StartedAt)
 
 	if err != nil {
@@ -72,6 +133,9 @@ func (r *Presentation) route(ctx *ext.Context, update *ext.Update) error {
 			Msg("failed.to.process.command")
 
 		errMessage := fmt.Sprintf("Err: something went wrong: %s", err.Error())
+		if c.job.cancelled() {
+			errMessage = fmt.Sprintf("Command #%d is cancelled", c.job.id)
+		}
 
 		var innerErr error
 
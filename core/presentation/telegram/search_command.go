@@ -0,0 +1,239 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/repository/db_repository"
+	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/shared"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/pkg/errors"
+)
+
+var (
+	FlagSearchFrom = optFlag{ // nolint: gochecknoglobals // FIXME
+		Long:        "from",
+		Short:       "f",
+		Description: "search only messages of user, @username or id",
+	}
+	FlagSearchChat = optFlag{ // nolint: gochecknoglobals // FIXME
+		Long:        "chat",
+		Short:       "t",
+		Description: "search in stored messages of chat with this id instead of current one",
+	}
+	FlagSearchPage = optFlag{ // nolint: gochecknoglobals // FIXME
+		Long:        "page",
+		Short:       "p",
+		Description: "page of results, starting from 1",
+	}
+)
+
+const (
+	searchPageSize    = 10
+	searchSnippetSize = 200
+	// searchIndexBatchSize is amount of messages stored before lemmas were stored, which are indexed at once
+	searchIndexBatchSize = 1000
+)
+
+// resolveUserID
+// Returns id of user by @username or by id.
+func resolveUserID(c *Context, user string) (int64, error) {
+	if strings.HasPrefix(user, "@") {
+		peer, err := c.extCtx.ResolveUsername(user)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to resolve username: %s", user)
+		}
+
+		if !peer.IsAUser() {
+			return 0, errors.Errorf("%s is not a user", user)
+		}
+
+		return peer.GetID(), nil
+	}
+
+	tgUserID, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse user id, pass @username or id")
+	}
+
+	return tgUserID, nil
+}
+
+// searchGetArgs
+// Parses filters of search, whole stored history is searched if --since is not passed.
+func searchGetArgs(c *Context) (*db_repository.MessagesGetInput, int, error) {
+	input := db_repository.MessagesGetInput{
+		TgChatID: c.update.EffectiveChat().GetID(),
+		Limit:    searchPageSize,
+	}
+	page := 1
+
+	if chatS, ok := c.Ops[FlagSearchChat.Long]; ok {
+		// Other chats may be private, so only owner can search there
+		err := c.requireRole(message_service.RoleOwner)
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+
+		tgChatID, err := strconv.ParseInt(chatS, 10, 64)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to parse chat flag")
+		}
+
+		input.TgChatID = tgChatID
+	}
+
+	if fromS, ok := c.Ops[FlagSearchFrom.Long]; ok {
+		tgUserID, err := resolveUserID(c, fromS)
+		if err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+
+		input.TgUserID = tgUserID
+	}
+
+	if sinceS, ok := c.Ops[FlagUploadStatsSince.Long]; ok {
+		since, err := time.ParseInLocation(time.DateOnly, sinceS, shared.TZTime)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to parse since flag")
+		}
+
+		input.Since = since.UTC()
+	}
+
+	if untilS, ok := c.Ops[FlagUploadStatsUntil.Long]; ok {
+		until, err := time.ParseInLocation(time.DateOnly, untilS, shared.TZTime)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to parse until flag")
+		}
+
+		input.Until = until.AddDate(0, 0, 1).UTC()
+	}
+
+	if pageS, ok := c.Ops[FlagSearchPage.Long]; ok {
+		var err error
+
+		page, err = strconv.Atoi(pageS)
+		if err != nil || page < 1 {
+			return nil, 0, errors.New("page must be positive number")
+		}
+	}
+
+	return &input, page, nil
+}
+
+// searchCommand
+// Searches stored messages by words in any form, results are paginated from newest to oldest.
+func (r *Presentation) searchCommand(c *Context) error {
+	query := strings.TrimSpace(c.Text)
+	if query == "" {
+		return c.replyWithError(errors.New("pass words to search after command"))
+	}
+
+	input, page, err := searchGetArgs(c)
+	if err != nil {
+		return c.replyWithError(err)
+	}
+
+	err = r.indexMessages(c, input.TgChatID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	wordsConfig, err := r.dbRepository.WordsConfigGet(c.extCtx, input.TgChatID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get words config")
+	}
+
+	terms, err := r.analiticsService.SearchTerms(query, &wordsConfig)
+	if err != nil {
+		return c.replyWithError(err)
+	}
+
+	foundCount, err := r.dbRepository.MessagesSearchCount(c.extCtx, input, terms)
+	if err != nil {
+		return errors.Wrap(err, "failed to count found messages")
+	}
+
+	if foundCount == 0 {
+		storedCount, err := r.dbRepository.MessagesCount(c.extCtx, input)
+		if err != nil {
+			return errors.Wrap(err, "failed to count stored messages")
+		}
+
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Nothing found among %d stored messages", storedCount)))
+	}
+
+	pagesCount := (foundCount + searchPageSize - 1) / searchPageSize
+	page = min(page, pagesCount)
+
+	found, err := r.dbRepository.MessagesSearch(c.extCtx, input, terms, (page-1)*searchPageSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to search messages")
+	}
+
+	users, err := r.dbRepository.UsersInChatGet(c.extCtx, input.TgChatID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get users")
+	}
+
+	nameGetter := users.GetNameGetter()
+
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("Found: %d, page %d/%d\n\n", foundCount, page, pagesCount))
+
+	for idx, message := range found {
+		snippet := message.Text
+		if utf8.RuneCountInString(snippet) > searchSnippetSize {
+			snippet = string([]rune(snippet)[:searchSnippetSize]) + "…"
+		}
+
+		text.WriteString(fmt.Sprintf(
+			"%d. %s, %s\n%s\n",
+			(page-1)*searchPageSize+idx+1,
+			nameGetter.GetName(message.TgUserID),
+			message.CreatedAt.In(shared.TZTime).Format("2006-01-02 15:04"),
+			snippet,
+		))
+
+		link := messageLink(c, message.TgChatID, message.TgID)
+		if link != "" {
+			text.WriteString(link + "\n")
+		}
+
+		text.WriteString("\n")
+	}
+
+	if page < pagesCount {
+		text.WriteString(fmt.Sprintf("Next page: --%s=%d\n", FlagSearchPage.Long, page+1))
+	}
+
+	return c.reply(ext.ReplyTextString(text.String()))
+}
+
+// indexMessages
+// Stores lemmas of messages of chat, which were stored before lemmas were stored, so they can be found.
+func (r *Presentation) indexMessages(c *Context, tgChatID int64) error {
+	for {
+		messages, err := r.dbRepository.MessagesNotIndexedGet(c.extCtx, tgChatID, searchIndexBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to get not indexed messages")
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		r.analiticsService.IndexMessages(messages)
+
+		err = r.dbRepository.MessagesLemmasUpdate(c.extCtx, messages)
+		if err != nil {
+			return errors.Wrap(err, "failed to index messages")
+		}
+	}
+}
@@ -1,15 +1,23 @@
 package telegram
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/shared"
+	"maps"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"fun_telegram/core/service/analitics"
 
 	"github.com/gotd/td/telegram/message"
 	"github.com/gotd/td/telegram/message/styling"
 	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
 	"github.com/pkg/errors"
 )
 
@@ -17,13 +25,23 @@ var (
 	FlagUploadStatsOffset = optFlag{ //nolint: gochecknoglobals // FIXME
 		Long:        "offset",
 		Short:       "o",
-		Description: "force message offset",
+		Description: "use only messages older than message with this id",
 	}
 	FlagUploadStatsDay = optFlag{ //nolint: gochecknoglobals // FIXME
 		Long:        "day",
 		Short:       "d",
 		Description: "max age of message to upload in days",
 	}
+	FlagUploadStatsSince = optFlag{ //nolint: gochecknoglobals // FIXME
+		Long:        "since",
+		Short:       "s",
+		Description: "use only messages since date, YYYY-MM-DD, overrides --day",
+	}
+	FlagUploadStatsUntil = optFlag{ //nolint: gochecknoglobals // FIXME
+		Long:        "until",
+		Short:       "u",
+		Description: "use only messages until date inclusive, YYYY-MM-DD",
+	}
 	FlagUploadStatsCount = optFlag{ //nolint: gochecknoglobals // FIXME
 		Long:        "count",
 		Short:       "c",
@@ -34,8 +52,38 @@ var (
 		Short:       "a",
 		Description: "anonymize names of users",
 	}
+	FlagStatsAnonymizeMapping = optFlag{ // nolint: gochecknoglobals // FIXME
+		Long:        "mapping",
+		Short:       "m",
+		Description: "send aliases of anonymized users to saved messages",
+	}
 )
 
+// anonymizeSalt
+// Returns salt of aliases from settings, or random one, which is generated on first use and stored.
+// Without salt aliases could be computed by anyone, who knows ids of members.
+func (r *Presentation) anonymizeSalt(c *Context) (string, error) {
+	if shared.AppSettings.AnonymizeSalt != "" {
+		return shared.AppSettings.AnonymizeSalt, nil
+	}
+
+	const saltSize = 32
+
+	random := make([]byte, saltSize)
+
+	_, err := rand.Read(random)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+
+	salt, err := r.dbRepository.SecretGetOrCreate(c.extCtx, message_service.SecretAnonymizeSalt, hex.EncodeToString(random))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return salt, nil
+}
+
 // compileStats
 // nolint: cyclop // don't care
 // TODO fix cyclop
@@ -48,38 +96,25 @@ func (r *Presentation) compileStats(c *Context, storage *message_service.Storage
 		Storage:   *storage,
 	}
 
-	report, err := r.analiticsService.AnaliseChat(c.extCtx, &analiseInput)
-	if err != nil {
-		return errors.Wrap(err, "failed to analise chat")
-	}
-
-	fileUploader := uploader.NewUploader(c.extCtx.Raw)
+	if anonymize {
+		salt, err := r.anonymizeSalt(c)
+		if err != nil {
+			return errors.Wrap(err, "failed to get anonymize salt")
+		}
 
-	if len(report.Images) == 0 {
-		return errors.Wrapf(err, "no images in report")
+		analiseInput.AnonymizeSalt = salt
 	}
 
-	firstFile, err := fileUploader.FromBytes(
-		c.extCtx,
-		report.Images[0].Filename(),
-		report.Images[0].Content,
-	)
+	report, err := r.analiticsService.AnaliseChat(c.extCtx, &analiseInput)
 	if err != nil {
-		return errors.WithStack(err)
+		return errors.Wrap(err, "failed to analise chat")
 	}
 
-	album := make([]message.MultiMediaOption, 0, 10)
-
-	for _, repostImage := range report.Images[1:] {
-		file, err := fileUploader.FromBytes(c.extCtx, repostImage.Filename(), repostImage.Content)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-
-		album = append(album, message.UploadedPhoto(file))
+	if len(report.Images) == 0 {
+		return errors.New("no images in report")
 	}
 
-	text := make([]styling.StyledTextOption, 0, 3)
+	text := make([]styling.StyledTextOption, 0, 3+len(report.DistinctiveWords))
 	text = append(text, styling.Plain(fmt.Sprintf("%s \n\n", GetChatName(c.update.EffectiveChat()))))
 
 	text = append(text,
@@ -90,6 +125,7 @@ Compiled in: %.2fs`,
 				time.Since(c.StartedAt).Seconds()),
 		),
 	)
+	text = appendDistinctiveWords(text, report.DistinctiveWords)
 
 	var requestBuilder *message.RequestBuilder
 	if c.Silent {
@@ -98,7 +134,111 @@ Compiled in: %.2fs`,
 		requestBuilder = c.extCtx.Sender.To(c.update.EffectiveChat().GetInputPeer())
 	}
 
-	_, err = requestBuilder.Album(c.extCtx, message.UploadedPhoto(firstFile, text...), album...)
+	err = sendImages(c, requestBuilder, report.Images, text...)
+	if err != nil {
+		return errors.Wrap(err, "failed to send images")
+	}
+
+	if _, ok := c.Ops[FlagStatsAnonymizeMapping.Long]; ok && len(report.Aliases) != 0 {
+		err = r.sendAliases(c, report.Aliases)
+		if err != nil {
+			return errors.Wrap(err, "failed to send aliases")
+		}
+	}
+
+	return nil
+}
+
+// appendDistinctiveWords
+// Appends table of distinctive words to caption, lines not fitting into caption limit are dropped.
+func appendDistinctiveWords(text []styling.StyledTextOption, lines []string) []styling.StyledTextOption {
+	// Caption of media is limited by 1024 characters, header and stats take about 150
+	const captionLimit = 850
+
+	if len(lines) == 0 {
+		return text
+	}
+
+	text = append(text, styling.Plain("\n\nDistinctive words:\n"))
+	length := 0
+
+	for _, line := range lines {
+		length += utf8.RuneCountInString(line) + 1
+		if length > captionLimit {
+			break
+		}
+
+		text = append(text, styling.Plain(line+"\n"))
+	}
+
+	return text
+}
+
+// sendImages
+// Sends images as albums, caption is attached to the first one.
+func sendImages(
+	c *Context,
+	requestBuilder *message.RequestBuilder,
+	images []analitics.File,
+	caption ...styling.StyledTextOption,
+) error {
+	// Telegram does not allow more than 10 media in one album
+	const albumLimit = 10
+
+	fileUploader := uploader.NewUploader(c.extCtx.Raw)
+
+	for chunk := range slices.Chunk(images, albumLimit) {
+		album := make([]message.MultiMediaOption, 0, len(chunk))
+
+		for idx, image := range chunk {
+			file, err := fileUploader.FromBytes(c.extCtx, image.Filename(), image.Content)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if idx == 0 {
+				album = append(album, message.UploadedPhoto(file, caption...))
+			} else {
+				album = append(album, message.UploadedPhoto(file))
+			}
+		}
+
+		_, err := requestBuilder.Album(c.extCtx, album[0], album[1:]...)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		caption = nil
+	}
+
+	return nil
+}
+
+// sendAliases
+// Sends aliases of anonymized users to saved messages, so only owner can match them.
+func (r *Presentation) sendAliases(c *Context, aliases map[string]string) error {
+	const maxMessageLen = 4000
+
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("Aliases for %s\n\n", GetChatName(c.update.EffectiveChat())))
+
+	for _, alias := range slices.Sorted(maps.Keys(aliases)) {
+		line := fmt.Sprintf("%s - %s\n", alias, aliases[alias])
+
+		if text.Len()+len(line) > maxMessageLen {
+			_, err := c.extCtx.SendMessage(c.extCtx.Self.ID, &tg.MessagesSendMessageRequest{Message: text.String()})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			text.Reset()
+		}
+
+		text.WriteString(line)
+	}
+
+	_, err := c.extCtx.SendMessage(c.extCtx.Self.ID, &tg.MessagesSendMessageRequest{Message: text.String()})
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -2,14 +2,13 @@ package telegram
 
 import (
 	"fmt"
+	"fun_telegram/core/repository/db_repository"
 	"fun_telegram/core/service/message_service"
 	"strconv"
 	"time"
 
 	"fun_telegram/core/shared"
 
-	"github.com/celestix/gotgproto/ext"
-	"github.com/gotd/td/telegram/query"
 	"github.com/gotd/td/telegram/query/messages"
 	"github.com/gotd/td/tg"
 	"github.com/guregu/null/v5"
@@ -23,17 +22,15 @@ const (
 )
 
 func (r *Presentation) updateUploadStatsMessage(
-	ctx *ext.Context,
+	c *Context,
+	bar progressMessage,
 	count int,
-	chatID int64,
-	msgID int,
-	chatPeer tg.InputPeerClass,
 	offset int,
 	startedAt time.Time,
 	lastDate time.Time,
 	maxCount int,
 ) {
-	zerolog.Ctx(ctx).Info().
+	zerolog.Ctx(c.extCtx).Info().
 		Int("count", count).
 		Msg("messages.batch.uploaded")
 
@@ -41,31 +38,34 @@ func (r *Presentation) updateUploadStatsMessage(
 	remainingCount := maxCount - count
 	speedSeconds := float64(count) / elapsed
 
-	_, err := ctx.EditMessage(chatID, &tg.MessagesEditMessageRequest{
-		Peer: chatPeer,
-		ID:   msgID,
-		Message: fmt.Sprintf(
-			`⚙️ Uploading messages
+	c.editProgressMessage(&bar, fmt.Sprintf(
+		`⚙️ Uploading messages
 
 Amount uploaded: %d, Remaining: %d
 Seconds elapsed: %.2f, Speed: %.2fmsg/s, ETA: %.1f minutes
 Offset: %d
 LastDate: %s`,
-			count,
-			remainingCount,
-			elapsed,
-			speedSeconds,
-			float64(remainingCount)/speedSeconds/60,
-			offset,
-			lastDate.String(),
-		),
-	})
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Stack().Err(err).Str("status", "failed.to.edit.message").Send()
-	}
+		count,
+		remainingCount,
+		elapsed,
+		speedSeconds,
+		float64(remainingCount)/speedSeconds/60,
+		offset,
+		lastDate.String(),
+	))
 }
 
 func statsGetArgs(c *Context) (getChatStorageInput, error) {
+	return getChatStorageArgs(c, shared.DefaultUploadCount, shared.DefaultUploadQueryAge)
+}
+
+// getChatStorageArgs
+// Parses window of messages from flags, defaults are used if flags are absent.
+func getChatStorageArgs( //nolint: cyclop // FIXME
+	c *Context,
+	defaultCount int,
+	defaultQueryAge time.Duration,
+) (getChatStorageInput, error) {
 	var input getChatStorageInput
 
 	const (
@@ -73,7 +73,7 @@ func statsGetArgs(c *Context) (getChatStorageInput, error) {
 	)
 
 	input.MaxElapsed = maxElapsed
-	input.MaxCount = shared.DefaultUploadCount
+	input.MaxCount = defaultCount
 
 	if userMaxCountS, ok := c.Ops[FlagUploadStatsCount.Long]; ok {
 		userMaxCount, err := strconv.Atoi(userMaxCountS)
@@ -88,7 +88,7 @@ func statsGetArgs(c *Context) (getChatStorageInput, error) {
 		}
 	}
 
-	maxQueryAge := shared.DefaultUploadQueryAge
+	maxQueryAge := defaultQueryAge
 
 	if userQueryAgeS, ok := c.Ops[FlagUploadStatsDay.Long]; ok {
 		userQueryAge, err := strconv.Atoi(userQueryAgeS)
@@ -105,23 +105,62 @@ func statsGetArgs(c *Context) (getChatStorageInput, error) {
 
 	input.QueryTill = time.Now().UTC().Add(-maxQueryAge)
 
-	return input, nil
-}
+	sinceS, hasSince := c.Ops[FlagUploadStatsSince.Long]
+	if hasSince {
+		since, err := time.ParseInLocation(time.DateOnly, sinceS, shared.TZTime)
+		if err != nil {
+			return getChatStorageInput{}, errors.Wrap(err, "failed to parse since flag")
+		}
 
-func (r *Presentation) appendMessage(c *Context, storage *message_service.Storage, elem messages.Elem) {
-	msg, ok := elem.Msg.(*tg.Message)
-	if !ok {
-		return
+		input.QueryTill = since.UTC()
+	}
+
+	if untilS, ok := c.Ops[FlagUploadStatsUntil.Long]; ok {
+		until, err := time.ParseInLocation(time.DateOnly, untilS, shared.TZTime)
+		if err != nil {
+			return getChatStorageInput{}, errors.Wrap(err, "failed to parse until flag")
+		}
+
+		// Until is inclusive, so the whole day is taken
+		input.QueryUntil = until.AddDate(0, 0, 1).UTC()
+
+		// Without since window of default age ends at until, not now
+		if !hasSince {
+			input.QueryTill = input.QueryUntil.Add(-maxQueryAge)
+		}
+
+		if !input.QueryUntil.After(input.QueryTill) {
+			return getChatStorageInput{}, errors.New("until must be after since")
+		}
 	}
 
+	if offsetS, ok := c.Ops[FlagUploadStatsOffset.Long]; ok {
+		offset, err := strconv.Atoi(offsetS)
+		if err != nil {
+			return getChatStorageInput{}, errors.Wrap(err, "failed to parse offset flag")
+		}
+
+		if offset < 0 {
+			return getChatStorageInput{}, errors.New("offset must not be negative")
+		}
+
+		input.OffsetID = offset
+	}
+
+	return input, nil
+}
+
+// newMessage
+// Converts telegram message to stored one, messages not from users are skipped.
+func newMessage(tgChatID int64, msg *tg.Message) (message_service.Message, bool) {
 	msgFrom, ok := msg.FromID.(*tg.PeerUser)
 	if !ok {
-		return
+		return message_service.Message{}, false
 	}
 
-	analiticsMessage := message_service.Message{
-		CreatedAt: time.Unix(int64(msg.Date), 0),
-		TgChatID:  c.update.EffectiveChat().GetID(),
+	message := message_service.Message{
+		CreatedAt: time.Unix(int64(msg.Date), 0).UTC(),
+		TgChatID:  tgChatID,
 		TgUserID:  msgFrom.UserID,
 		Text:      msg.Message,
 		TgID:      msg.ID,
@@ -130,10 +169,24 @@ func (r *Presentation) appendMessage(c *Context, storage *message_service.Storag
 	if msg.ReplyTo != nil {
 		messageReplyHeader, ok := msg.ReplyTo.(*tg.MessageReplyHeader)
 		if ok {
-			analiticsMessage.ReplyToTgMsgID = null.IntFrom(int64(messageReplyHeader.ReplyToMsgID))
+			message.ReplyToTgMsgID = null.IntFrom(int64(messageReplyHeader.ReplyToMsgID))
 		}
 	}
 
+	return message, true
+}
+
+func (r *Presentation) appendMessage(tgChatID int64, storage *message_service.Storage, elem messages.Elem) {
+	msg, ok := elem.Msg.(*tg.Message)
+	if !ok {
+		return
+	}
+
+	analiticsMessage, ok := newMessage(tgChatID, msg)
+	if !ok {
+		return
+	}
+
 	r.analiticsService.AppendMessage(storage, &analiticsMessage)
 }
 
@@ -141,139 +194,135 @@ type getChatStorageInput struct {
 	MaxElapsed time.Duration
 	MaxCount   int
 	QueryTill  time.Time
+	// QueryUntil and OffsetID are upper bounds of requested messages, they are ignored if empty.
+	QueryUntil time.Time
+	OffsetID   int
+}
+
+// isWindowed
+// Returns true if not only the most recent messages are requested.
+func (r *getChatStorageInput) isWindowed() bool {
+	return !r.QueryUntil.IsZero() || r.OffsetID != 0
+}
+
+func (r *getChatStorageInput) messagesGetInput(tgChatID int64) *db_repository.MessagesGetInput {
+	return &db_repository.MessagesGetInput{
+		TgChatID:   tgChatID,
+		Since:      r.QueryTill,
+		Until:      r.QueryUntil,
+		OffsetTgID: r.OffsetID,
+		Limit:      r.MaxCount,
+	}
 }
 
 func (r *Presentation) getChatStorage( //nolint: funlen // FIXME
 	c *Context,
 	input *getChatStorageInput,
 ) (*message_service.Storage, error) {
-	var (
-		barChatID    int64
-		barMessageID int
-		barPeer      tg.InputPeerClass
-	)
-
-	if !c.Silent {
-		barMessage, err := c.extCtx.Reply(c.update, ext.ReplyTextString("⚙️ Uploading messages"), nil)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		barMessageID = barMessage.ID
-		barChatID = c.update.EffectiveChat().GetID()
-		barPeer = c.update.EffectiveChat().GetInputPeer()
-	} else {
-		barMessage, err := c.extCtx.SendMessage(
-			c.extCtx.Self.ID,
-			&tg.MessagesSendMessageRequest{Message: "⚙️ Uploading messages"},
-		)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		barMessageID = barMessage.ID
-		barChatID = c.extCtx.Self.ID
-		barPeer = c.extCtx.Self.AsInputPeer()
+	bar, err := c.sendProgressMessage("⚙️ Uploading messages")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send progress message")
 	}
 
 	zerolog.Ctx(c.extCtx).
 		Info().
 		Msg("stats.upload.begin")
 
-	offset := 0
-	historyQuery := query.Messages(r.telegramAPI).GetHistory(c.update.EffectiveChat().GetInputPeer())
-	historyQuery.BatchSize(iterHistoryBatchSize)
-	historyQuery.OffsetID(offset)
-	historyIter := historyQuery.Iter()
-	startedAt := time.Now()
-	count := 0
+	chatID := c.update.EffectiveChat().GetID()
 
-	var lastDate time.Time
+	release, err := r.uploadQueue.acquire(c.jobCtx(), chatID, func(position int) {
+		c.editProgressMessage(&bar, fmt.Sprintf("⏳ Waiting for other uploads\n\nPosition in queue: %d", position))
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wait for upload slot")
+	}
 
-	storage := r.analiticsService.NewStorage()
+	defer release()
 
 	users, err := r.updateMembers(c.extCtx, c.update.EffectiveChat())
 	if err != nil {
 		return nil, c.replyWithError(errors.WithStack(err))
 	}
 
-	storage.Users = users
-	storage.UsersNameGetter = storage.Users.GetNameGetter()
-
-	for {
-		zerolog.Ctx(c.extCtx).Trace().Int("offset", offset).Msg("new.iteration")
+	err = r.dbRepository.UsersInChatUpsert(c.extCtx, users)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save users")
+	}
 
-		ok := historyIter.Next(c.extCtx)
-		if !ok {
-			err = historyIter.Err()
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
+	state, storedStats, err := r.getSyncState(c, chatID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 
-			zerolog.Ctx(c.extCtx).Info().Str("status", "all.messages.found").Send()
+	upload := historyUpload{
+		input:     input,
+		tgChatID:  chatID,
+		peer:      c.update.EffectiveChat().GetInputPeer(),
+		bar:       bar,
+		startedAt: time.Now(),
+		uploaded:  r.analiticsService.NewStorage(),
+	}
 
-			break
-		}
+	err = r.syncHistory(c, &upload, &state, &storedStats)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sync history")
+	}
 
-		elem := historyIter.Value()
-		offset = elem.Msg.GetID()
+	// Stored messages are read without requests to telegram, so other uploads can start
+	release()
 
-		msg, ok := elem.Msg.(*tg.Message)
-		if !ok {
-			continue
-		}
+	zerolog.Ctx(c.extCtx).Info().Str("status", "messages.uploaded").Int("count", upload.count).Send()
 
-		lastDate = time.Unix(int64(msg.Date), 0).In(shared.TZTime)
+	storage := r.analiticsService.NewStorage()
 
-		count++
+	storage.Messages, err = r.dbRepository.MessagesGet(c.extCtx, input.messagesGetInput(chatID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get stored messages")
+	}
 
-		r.appendMessage(c, storage, elem)
+	// Messages are scored once and stored, so slow scorers are not called on every analise
+	scored, err := r.analiticsService.ScoreToxicity(c.extCtx, storage.Messages)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to score toxicity")
+	}
 
-		if count%iterHistoryBatchSize == 0 {
-			time.Sleep(time.Millisecond * 800)
+	err = r.dbRepository.MessagesUpsert(c.extCtx, scored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save toxicity scores")
+	}
 
-			go r.updateUploadStatsMessage(
-				c.extCtx,
-				count,
-				barChatID,
-				barMessageID,
-				barPeer,
-				offset,
-				startedAt,
-				lastDate,
-				input.MaxCount,
-			)
-		}
+	storage.Users = users
+	storage.UsersNameGetter = storage.Users.GetNameGetter()
 
-		if !lastDate.After(input.QueryTill) || time.Since(startedAt) > input.MaxElapsed || count > input.MaxCount {
-			break
-		}
+	storage.WordsConfig, err = r.dbRepository.WordsConfigGet(c.extCtx, chatID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get words config")
 	}
 
-	zerolog.Ctx(c.extCtx).
-		Info().
-		Int("count", count).
-		Msg("waiting.for.uploading.to.repository")
-	zerolog.Ctx(c.extCtx).Info().Str("status", "messages.uploaded").Int("count", count).Send()
-
-	_, err = c.extCtx.EditMessage(barChatID, &tg.MessagesEditMessageRequest{
-		Peer: barPeer,
-		ID:   barMessageID,
-		Message: fmt.Sprintf(
-			"Messages uploaded!\n\n"+
-				"Amount: %d\n"+
-				"Elapsed: %.2fm\n"+
-				"LastDate: %s",
-			count,
-			time.Since(startedAt).Minutes(),
-			lastDate.In(shared.TZTime).String(),
-		),
-	})
+	c.editProgressMessage(&bar, fmt.Sprintf(
+		"Messages uploaded!\n\n"+
+			"Amount: %d\n"+
+			"Total with stored: %d\n"+
+			"Elapsed: %.2fm\n"+
+			"LastDate: %s",
+		upload.count,
+		len(storage.Messages),
+		time.Since(upload.startedAt).Minutes(),
+		upload.lastDate.In(shared.TZTime).String(),
+	))
+
+	return storage, nil
+}
+
+func (r *Presentation) flushUploaded(c *Context, uploaded *message_service.Storage) error {
+	err := r.dbRepository.MessagesUpsert(c.extCtx, uploaded.Messages)
 	if err != nil {
-		zerolog.Ctx(c.extCtx).Error().Stack().Err(err).Str("status", "failed.to.edit.message").Send()
+		return errors.Wrap(err, "failed to save uploaded messages")
 	}
 
-	return storage, nil
+	uploaded.Messages = uploaded.Messages[:0]
+
+	return nil
 }
 
 // statsCommand.
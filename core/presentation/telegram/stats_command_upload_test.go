@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"fun_telegram/core/shared"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statsTestFlags = []optFlag{ //nolint: gochecknoglobals // test data
+	FlagUploadStatsCount,
+	FlagUploadStatsDay,
+	FlagUploadStatsOffset,
+	FlagUploadStatsSince,
+	FlagUploadStatsUntil,
+}
+
+func getStatsTestArgs(t *testing.T, text string) (getChatStorageInput, error) {
+	t.Helper()
+
+	c := getOpt(text, statsTestFlags...)
+
+	return statsGetArgs(&c)
+}
+
+func TestUnit_Telegram_GetChatStorageArgsDefault_Ok(t *testing.T) {
+	t.Parallel()
+
+	input, err := getStatsTestArgs(t, "!stats")
+	require.NoError(t, err)
+
+	assert.Equal(t, shared.DefaultUploadCount, input.MaxCount)
+	assert.WithinDuration(t, time.Now().UTC().Add(-shared.DefaultUploadQueryAge), input.QueryTill, time.Minute)
+	assert.True(t, input.QueryUntil.IsZero())
+	assert.Zero(t, input.OffsetID)
+	assert.False(t, input.isWindowed())
+}
+
+func TestUnit_Telegram_GetChatStorageArgsCountAndDay_Ok(t *testing.T) {
+	t.Parallel()
+
+	input, err := getStatsTestArgs(t, "!stats -c=1000000 -d=10")
+	require.NoError(t, err)
+
+	assert.Equal(t, shared.MaxUploadCount, input.MaxCount)
+	assert.WithinDuration(t, time.Now().UTC().Add(-time.Hour*24*10), input.QueryTill, time.Minute)
+}
+
+func TestUnit_Telegram_GetChatStorageArgsSinceUntil_Ok(t *testing.T) {
+	t.Parallel()
+
+	input, err := getStatsTestArgs(t, "!stats --since=2023-12-01 --until=2023-12-31")
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2023, 12, 1, 0, 0, 0, 0, shared.TZTime).UTC(), input.QueryTill)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, shared.TZTime).UTC(), input.QueryUntil)
+}
+
+func TestUnit_Telegram_GetChatStorageArgsOnlyUntil_Ok(t *testing.T) {
+	t.Parallel()
+
+	input, err := getStatsTestArgs(t, "!stats --until=2023-12-31")
+	require.NoError(t, err)
+
+	until := time.Date(2024, 1, 1, 0, 0, 0, 0, shared.TZTime).UTC()
+	assert.Equal(t, until, input.QueryUntil)
+	assert.Equal(t, until.Add(-shared.DefaultUploadQueryAge), input.QueryTill)
+
+	input, err = getStatsTestArgs(t, "!stats -u=2023-12-31 -d=7")
+	require.NoError(t, err)
+
+	assert.Equal(t, until.Add(-time.Hour*24*7), input.QueryTill)
+}
+
+func TestUnit_Telegram_GetChatStorageArgsOffset_Ok(t *testing.T) {
+	t.Parallel()
+
+	input, err := getStatsTestArgs(t, "!stats -o=12345")
+	require.NoError(t, err)
+
+	assert.Equal(t, 12345, input.OffsetID)
+	assert.True(t, input.isWindowed())
+}
+
+func TestUnit_Telegram_GetChatStorageArgs_Err(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		"!stats --since=2024-01-10 --until=2024-01-01",
+		"!stats --since=01.01.2024",
+		"!stats --until=yesterday",
+		"!stats -o=-1",
+		"!stats -o=abc",
+		"!stats -c=many",
+		"!stats -d=week",
+	} {
+		_, err := getStatsTestArgs(t, text)
+		assert.Error(t, err, text)
+	}
+}
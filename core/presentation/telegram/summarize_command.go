@@ -3,31 +3,144 @@ package telegram
 import (
 	"fmt"
 	"fun_telegram/core/service/message_service"
-	"fun_telegram/core/supplier/gigachat_supplier"
+	"fun_telegram/core/service/summarize_service"
+	"fun_telegram/core/shared"
 	"slices"
 	"time"
 
 	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/tg"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 )
 
-func (r *Presentation) summarizeCommand(c *Context) error {
-	storage, err := r.getChatStorage(c, &getChatStorageInput{
-		MaxElapsed: time.Hour,
-		MaxCount:   200,
-		QueryTill:  time.Now().Add(-time.Hour * 24 * 30),
+var FlagSummarizeMine = optFlag{ // nolint: gochecknoglobals // FIXME
+	Long:        "mine",
+	Short:       "m",
+	Description: "summarize messages since your last message",
+}
+
+const (
+	summarizeDefaultCount    = 200
+	summarizeDefaultQueryAge = time.Hour * 24 * 30
+	// summarizeSearchCount is amount of messages, among which own last message or reply thread is searched
+	summarizeSearchCount = 5000
+	// summarizeMaxCount is max amount of messages summarized, they are split into chunks by token budget
+	summarizeMaxCount = 5000
+	// summarizeEditInterval is min interval between edits of streamed answer
+	summarizeEditInterval = 1500 * time.Millisecond
+)
+
+const (
+	summarizeChatPrompt = "Ты - умный бот, который сумаризирует переписку в телеграмме. " +
+		"Ниже тебе будут отправлены сообщения участников некое телеграмм чата. " +
+		"В ответе сумаризируй переписку, опиши диалоги, которые происхоидили, темы, что обсуждались"
+	summarizeThreadPrompt = "Ты - умный бот, который сумаризирует переписку в телеграмме. " +
+		"Ниже тебе будут отправлены сообщения одной ветки ответов некое телеграмм чата. " +
+		"В ответе сумаризируй ветку, опиши, с чего она началась, позиции участников и к чему они пришли"
+)
+
+// getRepliedTgID
+// Returns id of message, which command replies to, or 0.
+func getRepliedTgID(c *Context) int {
+	replyHeader, ok := c.update.EffectiveMessage.ReplyTo.(*tg.MessageReplyHeader)
+	if !ok {
+		return 0
+	}
+
+	return replyHeader.ReplyToMsgID
+}
+
+// formatMessageForLLM
+// Formats message as one line with its author and date.
+func formatMessageForLLM(storage *message_service.Storage, message *message_service.Message) string {
+	line := fmt.Sprintf("Автор: %s, Дата: %s, Сообщение: %s",
+		storage.UsersNameGetter.GetNameAndUsername(message.TgUserID),
+		message.CreatedAt.In(shared.TZTime).Format(time.DateTime),
+		message.Text,
+	)
+
+	if message.ReplyToTgUserID.Valid {
+		line += fmt.Sprintf(", В ответ на сообщение от: %s",
+			storage.UsersNameGetter.GetNameAndUsername(message.ReplyToTgUserID.Int64),
+		)
+	}
+
+	return line
+}
+
+// selectSummarizeMessages
+// Returns messages to summarize from newest to oldest according to mode of command.
+func selectSummarizeMessages(c *Context, messages message_service.Messages) (message_service.Messages, error) {
+	// Command itself is not summarized
+	commandTgID := c.update.EffectiveMessage.ID
+	messages = slices.DeleteFunc(messages, func(m message_service.Message) bool {
+		return m.TgID >= commandTgID
 	})
+
+	if repliedTgID := getRepliedTgID(c); repliedTgID != 0 {
+		thread := messages.ReplyThread(repliedTgID)
+		if thread == nil {
+			return nil, errors.New("replied message is not found among uploaded, increase --count or --day")
+		}
+
+		slices.SortFunc(thread, func(a, b message_service.Message) int {
+			return b.TgID - a.TgID
+		})
+
+		messages = thread
+	}
+
+	if _, ok := c.Ops[FlagSummarizeMine.Long]; ok {
+		var found bool
+
+		messages, found = messages.AfterLastOfUser(c.update.EffectiveUser().GetID())
+		if !found {
+			return nil, errors.New("your last message is not found among uploaded, increase --count or --day")
+		}
+	}
+
+	return messages[:min(len(messages), summarizeMaxCount)], nil
+}
+
+// summarizeCommand
+// Summarizes last messages of chat, messages since own last message or reply thread of replied message.
+func (r *Presentation) summarizeCommand(c *Context) error {
+	_, mine := c.Ops[FlagSummarizeMine.Long]
+	repliedTgID := getRepliedTgID(c)
+
+	defaultCount := summarizeDefaultCount
+	if mine || repliedTgID != 0 {
+		defaultCount = summarizeSearchCount
+	}
+
+	input, err := getChatStorageArgs(c, defaultCount, summarizeDefaultQueryAge)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	storage, err := r.getChatStorage(c, &input)
 	if err != nil {
 		return errors.Wrap(err, "failed to get chat storage")
 	}
 
-	messages := []gigachat_supplier.Message{{
-		Role: "system",
-		Content: "Ты - умный бот, который сумаризирует переписку в телеграмме. " +
-			"Ниже тебе будут отправлены сообщения участников некое телеграмм чата. " +
-			"В ответе сумаризируй переписку, опиши диалоги, которые происхоидили, темы, что обсуждались",
-	}}
-	slices.SortFunc(storage.Messages, func(a, b message_service.Message) int {
+	storage.Messages.ResolveReplies()
+
+	selected, err := selectSummarizeMessages(c, storage.Messages)
+	if err != nil {
+		return c.replyWithError(err)
+	}
+
+	if len(selected) == 0 {
+		return c.reply(ext.ReplyTextString("No messages to summarize"))
+	}
+
+	prompt := summarizeChatPrompt
+	if repliedTgID != 0 {
+		prompt = summarizeThreadPrompt
+	}
+
+	slices.SortFunc(selected, func(a, b message_service.Message) int {
 		if a.CreatedAt.Before(b.CreatedAt) {
 			return -1
 		}
@@ -35,21 +148,99 @@ func (r *Presentation) summarizeCommand(c *Context) error {
 		return 1
 	})
 
-	for _, message := range storage.Messages {
-		messages = append(messages, gigachat_supplier.Message{
-			Role: "user",
-			Content: fmt.Sprintf("Автор: %s, Дата: %s, Сообщение: %s",
-				storage.UsersNameGetter.GetNameAndUsername(message.TgUserID),
-				message.CreatedAt.String(),
-				message.Text,
-			),
-		})
+	lines := make([]string, 0, len(selected))
+
+	for _, message := range selected {
+		lines = append(lines, formatMessageForLLM(storage, &message))
+	}
+
+	bar, err := c.sendProgressMessage("⚙️ Summarizing messages")
+	if err != nil {
+		return errors.Wrap(err, "failed to send progress message")
+	}
+
+	startedAt := time.Now()
+	summarizeInput := summarize_service.SummarizeInput{
+		Prompt: prompt,
+		Lines:  lines,
+		OnProgress: func(progress summarize_service.Progress) {
+			r.updateSummarizeMessage(c, &bar, len(lines), startedAt, progress)
+		},
+	}
+
+	// In silent mode answer is sent to saved messages
+	placeholder, err := c.sendProgressMessage("💭")
+	if err != nil {
+		return errors.Wrap(err, "failed to send placeholder")
 	}
 
-	resp, err := r.gigachatSupplier.OneMessage(c.extCtx, messages)
+	answer := newThrottledEditor(c, placeholder, summarizeEditInterval)
+	summarizeInput.OnPartial = answer.update
+
+	resp, err := r.summarizeService.Summarize(c.jobCtx(), &summarizeInput)
 	if err != nil {
-		return errors.Wrap(err, "gigachat supplier")
+		return errors.Wrap(err, "failed to summarize")
 	}
 
-	return c.reply(ext.ReplyTextString(resp))
+	c.editProgressMessage(&bar, fmt.Sprintf(
+		"Messages summarized!\n\nAmount: %d\nElapsed: %.2fs",
+		len(lines),
+		time.Since(startedAt).Seconds(),
+	))
+
+	return r.sendAnswer(c, answer, resp)
+}
+
+// sendAnswer
+// Replaces placeholder with answer, part of answer not fitting into one message is sent as replies,
+// or to saved messages in silent mode.
+func (r *Presentation) sendAnswer(c *Context, placeholder *throttledEditor, answer string) error {
+	const maxMessageLen = 4000
+
+	runes := []rune(answer)
+
+	// Telegram fails to edit message with the same text
+	first := string(runes[:min(len(runes), maxMessageLen)])
+	if first != placeholder.text {
+		c.editMessage(&placeholder.message, first)
+	}
+
+	for chunk := range slices.Chunk(runes[min(len(runes), maxMessageLen):], maxMessageLen) {
+		_, err := c.sendProgressMessage(string(chunk))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Presentation) updateSummarizeMessage(
+	c *Context,
+	bar *progressMessage,
+	count int,
+	startedAt time.Time,
+	progress summarize_service.Progress,
+) {
+	zerolog.Ctx(c.extCtx).Info().
+		Str("stage", string(progress.Stage)).
+		Int("level", progress.Level).
+		Int("done", progress.Done).
+		Int("total", progress.Total).
+		Msg("summarize.chunk.completed")
+
+	c.editProgressMessage(bar, fmt.Sprintf(
+		`⚙️ Summarizing messages
+
+Amount: %d
+Stage: %s, Level: %d
+Chunks done: %d/%d
+Seconds elapsed: %.2f`,
+		count,
+		progress.Stage,
+		progress.Level,
+		progress.Done,
+		progress.Total,
+		time.Since(startedAt).Seconds(),
+	))
 }
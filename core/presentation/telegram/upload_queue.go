@@ -0,0 +1,112 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type uploadTicket struct {
+	tgChatID int64
+	started  bool
+}
+
+// uploadQueue
+// Limits amount of history uploads running at once, so flood waits are not triggered.
+// Uploads are started in order of arrival, upload of chat waits for previous upload of the same chat.
+type uploadQueue struct {
+	mu      sync.Mutex
+	limit   int
+	running map[int64]struct{}
+	waiting []*uploadTicket
+	// changed is closed and replaced on each change of queue
+	changed chan struct{}
+}
+
+func newUploadQueue(limit int) *uploadQueue {
+	return &uploadQueue{
+		limit:   max(limit, 1),
+		running: make(map[int64]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// schedule
+// Starts waiting uploads while there are free slots, must be called under lock.
+func (r *uploadQueue) schedule() {
+	r.waiting = slices.DeleteFunc(r.waiting, func(ticket *uploadTicket) bool {
+		if len(r.running) >= r.limit {
+			return false
+		}
+
+		if _, ok := r.running[ticket.tgChatID]; ok {
+			return false
+		}
+
+		r.running[ticket.tgChatID] = struct{}{}
+		ticket.started = true
+
+		return true
+	})
+
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *uploadQueue) release(ticket *uploadTicket) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ticket.started {
+		delete(r.running, ticket.tgChatID)
+	} else {
+		r.waiting = slices.DeleteFunc(r.waiting, func(other *uploadTicket) bool { return other == ticket })
+	}
+
+	r.schedule()
+}
+
+// acquire
+// Waits for upload slot of chat, onPosition is called with position in queue each time it changes.
+// Returned release must be called after upload is finished, it can be called more than once.
+func (r *uploadQueue) acquire(
+	ctx context.Context,
+	tgChatID int64,
+	onPosition func(position int),
+) (func(), error) {
+	ticket := &uploadTicket{tgChatID: tgChatID}
+
+	r.mu.Lock()
+	r.waiting = append(r.waiting, ticket)
+	r.schedule()
+	r.mu.Unlock()
+
+	lastPosition := 0
+
+	for {
+		r.mu.Lock()
+		started := ticket.started
+		position := slices.Index(r.waiting, ticket) + 1
+		changed := r.changed
+		r.mu.Unlock()
+
+		if started {
+			return sync.OnceFunc(func() { r.release(ticket) }), nil
+		}
+
+		if position != lastPosition {
+			onPosition(position)
+			lastPosition = position
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			r.release(ticket)
+
+			return nil, errors.WithStack(ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_UploadQueue_Acquire_Ok(t *testing.T) {
+	t.Parallel()
+
+	queue := newUploadQueue(2)
+	ctx := context.Background()
+	noPosition := func(int) { t.Error("upload must not wait") }
+
+	releaseFirst, err := queue.acquire(ctx, 1, noPosition)
+	require.NoError(t, err)
+
+	releaseOther, err := queue.acquire(ctx, 2, noPosition)
+	require.NoError(t, err)
+
+	positions := make(chan int, 10)
+	acquired := make(chan func())
+
+	go func() {
+		release, err := queue.acquire(ctx, 1, func(position int) { positions <- position })
+		assert.NoError(t, err)
+
+		acquired <- release
+	}()
+
+	assert.Equal(t, 1, <-positions)
+
+	// Slot is freed, but upload of the same chat is still running
+	releaseOther()
+
+	select {
+	case <-acquired:
+		t.Fatal("uploads of one chat must not be parallel")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	releaseFirst()
+
+	release := <-acquired
+	release()
+
+	assert.Empty(t, queue.running)
+	assert.Empty(t, queue.waiting)
+}
+
+func TestUnit_UploadQueue_AcquireCancelled_Err(t *testing.T) {
+	t.Parallel()
+
+	queue := newUploadQueue(1)
+
+	release, err := queue.acquire(context.Background(), 1, func(int) {})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err = queue.acquire(ctx, 2, func(position int) {
+		assert.Equal(t, 1, position)
+		cancel()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	release()
+
+	assert.Empty(t, queue.running)
+	assert.Empty(t, queue.waiting)
+}
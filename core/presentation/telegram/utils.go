@@ -1,9 +1,11 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -48,6 +50,113 @@ func (r *Context) reply(text ext.ReplyTextType) error {
 	return nil
 }
 
+// progressMessage
+// Message, which is edited to show progress of long command.
+type progressMessage struct {
+	chatID    int64
+	messageID int
+	peer      tg.InputPeerClass
+}
+
+// sendProgressMessage
+// Replies with progress message, in silent mode it is sent to saved messages.
+func (r *Context) sendProgressMessage(text string) (progressMessage, error) {
+	if !r.Silent {
+		msg, err := r.extCtx.Reply(r.update, ext.ReplyTextString(text), nil)
+		if err != nil {
+			return progressMessage{}, errors.WithStack(err)
+		}
+
+		return progressMessage{
+			chatID:    r.update.EffectiveChat().GetID(),
+			messageID: msg.ID,
+			peer:      r.update.EffectiveChat().GetInputPeer(),
+		}, nil
+	}
+
+	msg, err := r.extCtx.SendMessage(r.extCtx.Self.ID, &tg.MessagesSendMessageRequest{Message: text})
+	if err != nil {
+		return progressMessage{}, errors.WithStack(err)
+	}
+
+	return progressMessage{
+		chatID:    r.extCtx.Self.ID,
+		messageID: msg.ID,
+		peer:      r.extCtx.Self.AsInputPeer(),
+	}, nil
+}
+
+// editProgressMessage
+// Edits progress message and remembers progress of job, errors are only logged as progress is not essential.
+func (r *Context) editProgressMessage(msg *progressMessage, text string) {
+	if r.job != nil {
+		r.job.setProgress(text)
+	}
+
+	r.editMessage(msg, text)
+}
+
+// editMessage
+// Edits message sent by command, errors are only logged.
+func (r *Context) editMessage(msg *progressMessage, text string) {
+	_, err := r.extCtx.EditMessage(msg.chatID, &tg.MessagesEditMessageRequest{
+		Peer:    msg.peer,
+		ID:      msg.messageID,
+		Message: text,
+	})
+	if err != nil {
+		zerolog.Ctx(r.extCtx).Error().Stack().Err(err).Str("status", "failed.to.edit.message").Send()
+	}
+}
+
+// throttledEditor
+// Edits message with partial text at most once per interval, so flood limits of telegram are not hit.
+type throttledEditor struct {
+	c        *Context
+	message  progressMessage
+	interval time.Duration
+
+	editedAt time.Time
+	text     string
+}
+
+func newThrottledEditor(c *Context, message progressMessage, interval time.Duration) *throttledEditor {
+	return &throttledEditor{c: c, message: message, interval: interval}
+}
+
+func (r *throttledEditor) update(text string) {
+	if time.Since(r.editedAt) < r.interval {
+		return
+	}
+
+	// Message can not be longer than 4096 characters
+	const maxPartialLen = 4000
+
+	runes := []rune(text)
+	if len(runes) > maxPartialLen {
+		text = string(runes[:maxPartialLen]) + "…"
+	}
+
+	if text == r.text {
+		return
+	}
+
+	r.c.editMessage(&r.message, text)
+	r.editedAt = time.Now()
+	r.text = text
+}
+
+// jobCtx
+// Returns context, which is cancelled by !cancel. It is used for requests to telegram and llm,
+// while storage is accessed with extCtx, so already uploaded messages are saved on cancel.
+func (r *Context) jobCtx() context.Context {
+	if r.job == nil {
+		return r.extCtx
+	}
+
+	return r.job.ctx
+}
+
 func (r *Context) replyWithError(err error) error {
 	zerolog.Ctx(r.extCtx).Warn().Stack().Err(err).Msg("client.error.occurred")
 
@@ -0,0 +1,143 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/service/analitics"
+	"fun_telegram/core/shared"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/tg"
+	"github.com/pkg/errors"
+)
+
+// getTargetUser
+// Returns id of user, whose message is replied, or who is mentioned by @username.
+func (r *Presentation) getTargetUser(c *Context) (int64, error) {
+	if strings.HasPrefix(c.Text, "@") {
+		username, _, _ := strings.Cut(c.Text, " ")
+
+		user, err := c.extCtx.ResolveUsername(username)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to resolve username: %s", username)
+		}
+
+		if !user.IsAUser() {
+			return 0, errors.Errorf("%s is not a user", username)
+		}
+
+		return user.GetID(), nil
+	}
+
+	replyHeader, ok := c.update.EffectiveMessage.ReplyTo.(*tg.MessageReplyHeader)
+	if !ok || replyHeader.ReplyToMsgID == 0 {
+		return 0, errors.New("reply to message of user or pass @username")
+	}
+
+	replied, err := c.extCtx.GetMessages(
+		c.update.EffectiveChat().GetID(),
+		[]tg.InputMessageClass{&tg.InputMessageID{ID: replyHeader.ReplyToMsgID}},
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get replied message")
+	}
+
+	if len(replied) == 0 {
+		return 0, errors.New("replied message not found")
+	}
+
+	repliedMessage, ok := replied[0].(*tg.Message)
+	if !ok {
+		return 0, errors.New("replied message is empty")
+	}
+
+	fromUser, ok := repliedMessage.FromID.(*tg.PeerUser)
+	if !ok {
+		return 0, errors.New("replied message is not from user")
+	}
+
+	return fromUser.UserID, nil
+}
+
+func compileWhoisText(report *analitics.AnaliseUserReport) string {
+	var text strings.Builder
+
+	text.WriteString(fmt.Sprintf("%s\n\n", report.Name))
+	text.WriteString(fmt.Sprintf("Status: %s\n", report.Status))
+	text.WriteString(fmt.Sprintf("Messages: %d\n", report.MessagesCount))
+
+	if report.MessagesCount == 0 {
+		return text.String()
+	}
+
+	text.WriteString(fmt.Sprintf("Words: %d\n", report.WordsCount))
+	text.WriteString(fmt.Sprintf("Toxic words: %.2f%%\n", report.ToxicityPercent()))
+	text.WriteString(fmt.Sprintf("First message: %s\n", report.FirstMessageAt.In(shared.TZTime).Format(time.DateOnly)))
+	text.WriteString(fmt.Sprintf("Last message: %s\n", report.LastMessageAt.In(shared.TZTime).Format(time.DateOnly)))
+
+	if len(report.TopWords) != 0 {
+		words := make([]string, 0, len(report.TopWords))
+		for _, word := range report.TopWords {
+			words = append(words, fmt.Sprintf("%s (%d)", word.Word, word.Count))
+		}
+
+		text.WriteString(fmt.Sprintf("Top words: %s\n", strings.Join(words, ", ")))
+	}
+
+	return text.String()
+}
+
+// whoisCommand
+// Compiles personal report of user from messages of this chat.
+func (r *Presentation) whoisCommand(c *Context) error {
+	tgUserID, err := r.getTargetUser(c)
+	if err != nil {
+		return c.replyWithError(errors.Wrap(err, "failed to get target user"))
+	}
+
+	input, err := statsGetArgs(c)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	storage, err := r.getChatStorage(c, &input)
+	if err != nil {
+		return errors.Wrap(err, "failed to get chat storage")
+	}
+
+	report, err := r.analiticsService.AnaliseUser(c.extCtx, &analitics.AnaliseUserInput{
+		TgChatID: c.update.EffectiveChat().GetID(),
+		TgUserID: tgUserID,
+		Storage:  *storage,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to analise user")
+	}
+
+	var requestBuilder *message.RequestBuilder
+	if c.Silent {
+		requestBuilder = c.extCtx.Sender.Self()
+	} else {
+		requestBuilder = c.extCtx.Sender.To(c.update.EffectiveChat().GetInputPeer())
+	}
+
+	text := compileWhoisText(&report)
+
+	if len(report.Images) == 0 {
+		_, err = requestBuilder.Text(c.extCtx, text)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}
+
+	err = sendImages(c, requestBuilder, report.Images, styling.Plain(text))
+	if err != nil {
+		return errors.Wrap(err, "failed to send images")
+	}
+
+	return nil
+}
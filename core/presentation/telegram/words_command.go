@@ -0,0 +1,172 @@
+package telegram
+
+import (
+	"fmt"
+	"fun_telegram/core/service/message_service"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/pkg/errors"
+)
+
+var FlagWordsGlobal = optFlag{ // nolint: gochecknoglobals // FIXME
+	Long:        "global",
+	Short:       "g",
+	Description: "change settings of all chats",
+}
+
+// wordsConfigChatID
+// Returns chat, which words config is changed by command, global config is changed only by owner.
+func wordsConfigChatID(c *Context) (int64, error) {
+	if _, ok := c.Ops[FlagWordsGlobal.Long]; ok {
+		err := c.requireRole(message_service.RoleOwner)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+
+		return message_service.GlobalTgChatID, nil
+	}
+
+	return c.update.EffectiveChat().GetID(), nil
+}
+
+// stopWordsCommand
+// Adds, removes or lists stop words, words are stored as lemmas.
+func (r *Presentation) stopWordsCommand(c *Context) error {
+	action, text, _ := strings.Cut(c.Text, " ")
+	words := strings.Fields(text)
+
+	chatID, err := wordsConfigChatID(c)
+	if err != nil {
+		return c.replyWithError(err)
+	}
+
+	switch action {
+	case "add", "remove":
+		if len(words) == 0 {
+			return c.reply(ext.ReplyTextString("Err: no words passed"))
+		}
+
+		lemmas := make([]string, 0, len(words))
+
+		for _, word := range words {
+			lemma := r.analiticsService.Lemma(word)
+			if lemma == "" {
+				continue
+			}
+
+			err = r.dbRepository.StopWordUpsert(c.extCtx, &message_service.StopWord{
+				TgChatID: chatID,
+				Word:     lemma,
+				Removed:  action == "remove",
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			lemmas = append(lemmas, lemma)
+		}
+
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Stop words updated: %s", strings.Join(lemmas, ", "))))
+	case "list", "":
+		config, err := r.dbRepository.WordsConfigGet(c.extCtx, chatID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		var added, removed []string
+
+		for _, stopWord := range config.StopWords {
+			if stopWord.TgChatID != chatID {
+				continue
+			}
+
+			if stopWord.Removed {
+				removed = append(removed, stopWord.Word)
+			} else {
+				added = append(added, stopWord.Word)
+			}
+		}
+
+		return c.reply(ext.ReplyTextString(fmt.Sprintf(
+			"Added stop words: %s\nRemoved stop words: %s",
+			strings.Join(added, ", "),
+			strings.Join(removed, ", "),
+		)))
+	default:
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Err: unknown action: %s", action)))
+	}
+}
+
+// lemmasCommand
+// Sets, resets or lists lemma overrides, words are stored as lemmas.
+func (r *Presentation) lemmasCommand(c *Context) error {
+	action, text, _ := strings.Cut(c.Text, " ")
+	words := strings.Fields(text)
+
+	chatID, err := wordsConfigChatID(c)
+	if err != nil {
+		return c.replyWithError(err)
+	}
+
+	switch action {
+	case "set":
+		if len(words) != 2 {
+			return c.reply(ext.ReplyTextString("Err: pass word and its replacement"))
+		}
+
+		override := message_service.LemmaOverride{
+			TgChatID:    chatID,
+			Lemma:       r.analiticsService.Lemma(words[0]),
+			Replacement: r.analiticsService.Lemma(words[1]),
+		}
+
+		err = r.dbRepository.LemmaOverrideUpsert(c.extCtx, &override)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return c.reply(ext.ReplyTextString(
+			fmt.Sprintf("Lemma override set: %s -> %s", override.Lemma, override.Replacement),
+		))
+	case "reset":
+		if len(words) != 1 {
+			return c.reply(ext.ReplyTextString("Err: pass one word"))
+		}
+
+		lemma := r.analiticsService.Lemma(words[0])
+
+		// Override to itself cancels overrides of global settings and defaults
+		err = r.dbRepository.LemmaOverrideUpsert(c.extCtx, &message_service.LemmaOverride{
+			TgChatID:    chatID,
+			Lemma:       lemma,
+			Replacement: lemma,
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Lemma override reset: %s", lemma)))
+	case "list", "":
+		config, err := r.dbRepository.WordsConfigGet(c.extCtx, chatID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		var text strings.Builder
+
+		text.WriteString("Lemma overrides:\n")
+
+		for _, override := range config.LemmaOverrides {
+			if override.TgChatID != chatID || override.Lemma == override.Replacement {
+				continue
+			}
+
+			text.WriteString(fmt.Sprintf("%s -> %s\n", override.Lemma, override.Replacement))
+		}
+
+		return c.reply(ext.ReplyTextString(text.String()))
+	default:
+		return c.reply(ext.ReplyTextString(fmt.Sprintf("Err: unknown action: %s", action)))
+	}
+}
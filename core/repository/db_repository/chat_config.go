@@ -0,0 +1,72 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+func (r *Repository) ChatSettingsUpsert(ctx context.Context, settings *message_service.ChatSettings) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(settings).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert chat settings")
+	}
+
+	return nil
+}
+
+func (r *Repository) CommandRuleUpsert(ctx context.Context, rule *message_service.CommandRule) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(rule).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert command rule")
+	}
+
+	return nil
+}
+
+// CommandRuleDelete
+// Deletes rule of command, returns false if there was no such rule.
+func (r *Repository) CommandRuleDelete(ctx context.Context, tgChatID int64, command string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("tg_chat_id = ? AND command = ?", tgChatID, command).
+		Delete(&message_service.CommandRule{})
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, "failed to delete command rule")
+	}
+
+	return result.RowsAffected != 0, nil
+}
+
+// ChatConfigGet
+// Returns settings and command rules of chat, settings are zero if chat was not configured.
+func (r *Repository) ChatConfigGet(ctx context.Context, tgChatID int64) (message_service.ChatConfig, error) {
+	config := message_service.ChatConfig{Settings: message_service.ChatSettings{TgChatID: tgChatID}}
+
+	err := r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Limit(1).
+		Find(&config.Settings).
+		Error
+	if err != nil {
+		return message_service.ChatConfig{}, errors.Wrap(err, "failed to get chat settings")
+	}
+
+	err = r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Order("command").
+		Find(&config.Rules).
+		Error
+	if err != nil {
+		return message_service.ChatConfig{}, errors.Wrap(err, "failed to get command rules")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,72 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+func (r *Repository) GrantUpsert(ctx context.Context, grant *message_service.Grant) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(grant).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert grant")
+	}
+
+	return nil
+}
+
+// GrantDelete
+// Deletes grant, returns false if there was no such grant.
+func (r *Repository) GrantDelete(ctx context.Context, grant *message_service.Grant) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("tg_chat_id = ? AND tg_user_id = ?", grant.TgChatID, grant.TgUserID).
+		Delete(&message_service.Grant{})
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, "failed to delete grant")
+	}
+
+	return result.RowsAffected != 0, nil
+}
+
+// GrantsGet
+// Returns global and chat grants, global ones go first.
+func (r *Repository) GrantsGet(ctx context.Context, tgChatID int64) ([]message_service.Grant, error) {
+	var grants []message_service.Grant
+
+	err := r.db.WithContext(ctx).
+		Where("tg_chat_id IN ?", []int64{message_service.GlobalTgChatID, tgChatID}).
+		Order("tg_chat_id = 0 DESC, created_at").
+		Find(&grants).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grants")
+	}
+
+	return grants, nil
+}
+
+// GrantExists
+// Returns true if user is granted access in chat or in all chats.
+func (r *Repository) GrantExists(ctx context.Context, tgChatID int64, tgUserID int64) (bool, error) {
+	var count int64
+
+	err := r.db.WithContext(ctx).
+		Model(&message_service.Grant{}).
+		Where(
+			"tg_chat_id IN ? AND tg_user_id = ?",
+			[]int64{message_service.GlobalTgChatID, tgChatID},
+			tgUserID,
+		).
+		Count(&count).
+		Error
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count grants")
+	}
+
+	return count != 0, nil
+}
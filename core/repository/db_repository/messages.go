@@ -0,0 +1,175 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// messagesUpsertColumns are overwritten on conflict, toxicity and lemmas are kept, if they are not passed.
+var messagesUpsertColumns = []string{ //nolint: gochecknoglobals // FIXME
+	"created_at",
+	"tg_user_id",
+	"text",
+	"words_count",
+	"reply_to_tg_msg_id",
+	"reply_to_tg_user_id",
+}
+
+// MessagesUpsert
+// Saves messages, stored ones are updated.
+// Times are stored in UTC, as sqlite compares them as text.
+func (r *Repository) MessagesUpsert(ctx context.Context, messages message_service.Messages) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	for idx := range messages {
+		messages[idx].CreatedAt = messages[idx].CreatedAt.UTC()
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tg_chat_id"}, {Name: "tg_id"}},
+			DoUpdates: append(
+				clause.AssignmentColumns(messagesUpsertColumns),
+				// Messages are read without lemmas, so lemmas are kept, if they are not passed
+				clause.Assignment{
+					Column: clause.Column{Name: "lemmas"},
+					Value:  gorm.Expr("COALESCE(excluded.lemmas, messages.lemmas)"),
+				},
+				clause.Assignment{
+					Column: clause.Column{Name: "toxicity_score"},
+					Value:  gorm.Expr("COALESCE(excluded.toxicity_score, messages.toxicity_score)"),
+				},
+				clause.Assignment{
+					Column: clause.Column{Name: "toxicity_scorer"},
+					Value: gorm.Expr(
+						"CASE WHEN excluded.toxicity_score IS NULL " +
+							"THEN messages.toxicity_scorer ELSE excluded.toxicity_scorer END",
+					),
+				},
+			),
+		}).
+		CreateInBatches(messages, upsertBatchSize).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert messages")
+	}
+
+	return nil
+}
+
+type MessagesGetInput struct {
+	TgChatID int64
+	Since    time.Time
+	// Until and OffsetTgID are upper bounds of messages, they are ignored if empty.
+	Until      time.Time
+	OffsetTgID int
+	// TgUserID filters messages by author, ignored if empty.
+	TgUserID int64
+	Limit    int
+}
+
+func (r *Repository) messagesQuery(ctx context.Context, input *MessagesGetInput) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&message_service.Message{}).
+		Where("tg_chat_id = ? AND created_at > ?", input.TgChatID, input.Since.UTC())
+
+	if !input.Until.IsZero() {
+		query = query.Where("created_at < ?", input.Until.UTC())
+	}
+
+	if input.OffsetTgID != 0 {
+		query = query.Where("tg_id < ?", input.OffsetTgID)
+	}
+
+	if input.TgUserID != 0 {
+		query = query.Where("tg_user_id = ?", input.TgUserID)
+	}
+
+	return query
+}
+
+// MessagesGet
+// Returns newest messages of chat created after Since, ordered from newest to oldest.
+// Lemmas are not read, as they are only used by search.
+func (r *Repository) MessagesGet(ctx context.Context, input *MessagesGetInput) (message_service.Messages, error) {
+	var messages message_service.Messages
+
+	query := r.messagesQuery(ctx, input).Omit("lemmas").Order("tg_id DESC")
+
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+
+	err := query.Find(&messages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find messages")
+	}
+
+	return messages, nil
+}
+
+// MessagesCount
+// Returns amount of messages, which MessagesGet would return without limit.
+func (r *Repository) MessagesCount(ctx context.Context, input *MessagesGetInput) (int, error) {
+	var count int64
+
+	err := r.messagesQuery(ctx, input).Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count messages")
+	}
+
+	return int(count), nil
+}
+
+type MessagesStats struct {
+	Count int64
+
+	Newest message_service.Message
+	Oldest message_service.Message
+}
+
+// MessagesGetStats
+// Returns amount of stored messages of chat together with its newest and oldest message.
+func (r *Repository) MessagesGetStats(ctx context.Context, tgChatID int64) (MessagesStats, error) {
+	var stats MessagesStats
+
+	err := r.db.WithContext(ctx).
+		Model(&message_service.Message{}).
+		Where("tg_chat_id = ?", tgChatID).
+		Count(&stats.Count).
+		Error
+	if err != nil {
+		return MessagesStats{}, errors.Wrap(err, "failed to count messages")
+	}
+
+	if stats.Count == 0 {
+		return stats, nil
+	}
+
+	err = r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Order("tg_id DESC").
+		Take(&stats.Newest).
+		Error
+	if err != nil {
+		return MessagesStats{}, errors.Wrap(err, "failed to get newest message")
+	}
+
+	err = r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Order("tg_id ASC").
+		Take(&stats.Oldest).
+		Error
+	if err != nil {
+		return MessagesStats{}, errors.Wrap(err, "failed to get oldest message")
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,86 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const upsertBatchSize = 500
+
+type Repository struct {
+	db *gorm.DB
+}
+
+func New(ctx context.Context, path string) (*Repository, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create database dir")
+	}
+
+	db, err := gorm.Open(
+		sqlite.Open(path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"),
+		&gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+			// Times are compared as text by sqlite, so all of them are stored in one zone
+			NowFunc: func() time.Time { return time.Now().UTC() },
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sql db")
+	}
+
+	// SQLite allows only one writer, so all queries are serialized through one connection.
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.WithContext(ctx).AutoMigrate(
+		&message_service.Message{},
+		&message_service.UserInChat{},
+		&message_service.SyncState{},
+		&message_service.StopWord{},
+		&message_service.LemmaOverride{},
+		&message_service.Grant{},
+		&message_service.ChatSettings{},
+		&message_service.CommandRule{},
+		&message_service.Secret{},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to migrate database")
+	}
+
+	err = migrateMessagesSearch(ctx, db)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	zerolog.Ctx(ctx).Info().Str("path", path).Msg("database.opened")
+
+	return &Repository{db: db}, nil
+}
+
+func (r *Repository) Close() error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return errors.Wrap(err, "failed to get sql db")
+	}
+
+	err = sqlDB.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to close database")
+	}
+
+	return nil
+}
@@ -0,0 +1,390 @@
+package db_repository
+
+import (
+	"fun_telegram/core/service/message_service"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func getRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	r, err := New(test_utils.GetLoggedContext(), filepath.Join(t.TempDir(), "fun.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { require.NoError(t, r.Close()) })
+
+	return r
+}
+
+func TestUnit_DbRepository_MessagesUpsert_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+	now := time.Now().UTC()
+
+	err := r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 10, CreatedAt: now.Add(-time.Hour), Text: "first"},
+		{TgChatID: 1, TgID: 11, CreatedAt: now, Text: "second"},
+		{TgChatID: 2, TgID: 10, CreatedAt: now, Text: "other chat"},
+	})
+	require.NoError(t, err)
+
+	err = r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 11, CreatedAt: now, Text: "edited", WordsCount: 1},
+	})
+	require.NoError(t, err)
+
+	messages, err := r.MessagesGet(ctx, &MessagesGetInput{TgChatID: 1, Since: now.Add(-time.Hour * 2)})
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "edited", messages[0].Text)
+	assert.Equal(t, uint64(1), messages[0].WordsCount)
+	assert.Equal(t, "first", messages[1].Text)
+
+	stats, err := r.MessagesGetStats(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Count)
+	assert.Equal(t, 11, stats.Newest.TgID)
+	assert.Equal(t, 10, stats.Oldest.TgID)
+}
+
+func TestUnit_DbRepository_MessagesGetWindow_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+	now := time.Now().UTC()
+
+	messages := make(message_service.Messages, 0, 10)
+	for idx := range 10 {
+		messages = append(messages, message_service.Message{
+			TgChatID:  1,
+			TgID:      idx + 1,
+			TgUserID:  int64(10 + idx%2),
+			CreatedAt: now.Add(-time.Hour * time.Duration(10-idx)),
+		})
+	}
+
+	require.NoError(t, r.MessagesUpsert(ctx, messages))
+
+	input := MessagesGetInput{
+		TgChatID: 1,
+		Since:    now.Add(-time.Hour*8 - time.Minute),
+		Until:    now.Add(-time.Hour * 2),
+	}
+
+	count, err := r.MessagesCount(ctx, &input)
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	input.OffsetTgID = 5
+	input.Limit = 1
+
+	got, err := r.MessagesGet(ctx, &input)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 4, got[0].TgID)
+
+	input.TgUserID = 10
+
+	got, err = r.MessagesGet(ctx, &input)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 3, got[0].TgID)
+}
+
+func TestUnit_DbRepository_UsersInChatUpsert_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	err := r.UsersInChatUpsert(ctx, message_service.UsersInChat{
+		{TgChatID: 1, TgID: 100, TgName: "Masha", Status: message_service.Plain},
+		{TgChatID: 2, TgID: 100, TgName: "Masha", Status: message_service.Admin},
+	})
+	require.NoError(t, err)
+
+	err = r.UsersInChatUpsert(ctx, message_service.UsersInChat{
+		{TgChatID: 1, TgID: 100, TgName: "Masha", Status: message_service.Left},
+	})
+	require.NoError(t, err)
+
+	users, err := r.UsersInChatGet(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, message_service.Left, users[0].Status)
+}
+
+func TestUnit_DbRepository_SyncStateUpsert_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	state, err := r.SyncStateGet(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, state.IsEmpty())
+	assert.Equal(t, int64(1), state.TgChatID)
+
+	state.NewestTgID = 100
+	state.OldestTgID = 10
+	state.GapTopTgID = 150
+	state.GapOffsetTgID = 120
+
+	require.NoError(t, r.SyncStateUpsert(ctx, &state))
+
+	state.CloseGap()
+	require.NoError(t, r.SyncStateUpsert(ctx, &state))
+
+	state, err = r.SyncStateGet(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, state.HasGap())
+	assert.Equal(t, 150, state.NewestTgID)
+	assert.Equal(t, 10, state.OldestTgID)
+}
+
+func TestUnit_DbRepository_WordsConfigGet_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	for _, stopWord := range []message_service.StopWord{
+		{TgChatID: 1, Word: "кот", Removed: true},
+		{TgChatID: message_service.GlobalTgChatID, Word: "кот"},
+		{TgChatID: 2, Word: "собака"},
+	} {
+		require.NoError(t, r.StopWordUpsert(ctx, &stopWord))
+	}
+
+	require.NoError(t, r.LemmaOverrideUpsert(ctx, &message_service.LemmaOverride{
+		TgChatID:    1,
+		Lemma:       "котик",
+		Replacement: "кот",
+	}))
+
+	config, err := r.WordsConfigGet(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []message_service.StopWord{
+		{TgChatID: message_service.GlobalTgChatID, Word: "кот"},
+		{TgChatID: 1, Word: "кот", Removed: true},
+	}, config.StopWords)
+	assert.Equal(t, []message_service.LemmaOverride{
+		{TgChatID: 1, Lemma: "котик", Replacement: "кот"},
+	}, config.LemmaOverrides)
+}
+
+func TestUnit_DbRepository_Grants_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	require.NoError(t, r.GrantUpsert(ctx, &message_service.Grant{TgChatID: 1, TgUserID: 100}))
+	require.NoError(t, r.GrantUpsert(ctx, &message_service.Grant{TgChatID: 1, TgUserID: 100}))
+	require.NoError(t, r.GrantUpsert(ctx, &message_service.Grant{TgChatID: message_service.GlobalTgChatID, TgUserID: 200}))
+
+	granted, err := r.GrantExists(ctx, 2, 200)
+	require.NoError(t, err)
+	assert.True(t, granted)
+
+	granted, err = r.GrantExists(ctx, 2, 100)
+	require.NoError(t, err)
+	assert.False(t, granted)
+
+	grants, err := r.GrantsGet(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, int64(200), grants[0].TgUserID)
+
+	deleted, err := r.GrantDelete(ctx, &message_service.Grant{TgChatID: 1, TgUserID: 100})
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = r.GrantDelete(ctx, &message_service.Grant{TgChatID: 1, TgUserID: 100})
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestUnit_DbRepository_ChatConfig_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	config, err := r.ChatConfigGet(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), config.Settings.TgChatID)
+	assert.False(t, config.Settings.Disabled)
+	assert.Empty(t, config.Rules)
+
+	require.NoError(t, r.ChatSettingsUpsert(ctx, &message_service.ChatSettings{TgChatID: 1, Disabled: true}))
+	require.NoError(t, r.ChatSettingsUpsert(ctx, &message_service.ChatSettings{TgChatID: 1, RepliesDisabled: true}))
+	require.NoError(t, r.CommandRuleUpsert(ctx, &message_service.CommandRule{
+		TgChatID: 1,
+		Command:  "stats",
+		Access:   message_service.CommandAccessDeny,
+	}))
+	require.NoError(t, r.CommandRuleUpsert(ctx, &message_service.CommandRule{TgChatID: 2, Command: "ask"}))
+
+	config, err = r.ChatConfigGet(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, config.Settings.Disabled)
+	assert.True(t, config.Settings.RepliesDisabled)
+	require.Len(t, config.Rules, 1)
+	assert.Equal(t, message_service.CommandAccessDeny, config.Rules[0].Access)
+
+	deleted, err := r.CommandRuleDelete(ctx, 1, "stats")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = r.CommandRuleDelete(ctx, 1, "stats")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestUnit_DbRepository_SecretGetOrCreate_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+
+	secret, err := r.SecretGetOrCreate(ctx, message_service.SecretAnonymizeSalt, "first")
+	require.NoError(t, err)
+	assert.Equal(t, "first", secret)
+
+	secret, err = r.SecretGetOrCreate(ctx, message_service.SecretAnonymizeSalt, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "first", secret)
+}
+
+func TestUnit_DbRepository_MessagesGetNotUTC_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+	zone := time.FixedZone("MSK", 3*60*60)
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	err := r.MessagesUpsert(ctx, message_service.Messages{
+		// 11:00 UTC, but 14:00 in its zone
+		{TgChatID: 1, TgID: 10, CreatedAt: since.Add(-time.Hour).In(zone)},
+		{TgChatID: 1, TgID: 11, CreatedAt: since.Add(time.Hour).In(zone)},
+	})
+	require.NoError(t, err)
+
+	messages, err := r.MessagesGet(ctx, &MessagesGetInput{TgChatID: 1, Since: since})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, 11, messages[0].TgID)
+
+	messages, err = r.MessagesGet(ctx, &MessagesGetInput{TgChatID: 1, Since: since.Add(-time.Hour * 2).In(zone)})
+	require.NoError(t, err)
+	assert.Len(t, messages, 2)
+}
+
+func TestUnit_DbRepository_MessagesUpsertKeepsToxicity_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+	now := time.Now().UTC()
+
+	err := r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 10, CreatedAt: now, ToxicityScore: null.FloatFrom(0.5), ToxicityScorer: "regex:1"},
+	})
+	require.NoError(t, err)
+
+	// Uploaded again, not scored yet
+	err = r.MessagesUpsert(ctx, message_service.Messages{{TgChatID: 1, TgID: 10, CreatedAt: now, Text: "edited"}})
+	require.NoError(t, err)
+
+	messages, err := r.MessagesGet(ctx, &MessagesGetInput{TgChatID: 1})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "edited", messages[0].Text)
+	assert.Equal(t, null.FloatFrom(0.5), messages[0].ToxicityScore)
+	assert.Equal(t, "regex:1", messages[0].ToxicityScorer)
+
+	err = r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 10, CreatedAt: now, ToxicityScore: null.FloatFrom(0.2), ToxicityScorer: "lexicon:1"},
+	})
+	require.NoError(t, err)
+
+	messages, err = r.MessagesGet(ctx, &MessagesGetInput{TgChatID: 1})
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, null.FloatFrom(0.2), messages[0].ToxicityScore)
+	assert.Equal(t, "lexicon:1", messages[0].ToxicityScorer)
+}
+
+func TestUnit_DbRepository_MessagesSearch_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	r := getRepository(t)
+	now := time.Now().UTC()
+
+	err := r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 10, CreatedAt: now, Text: "dog", Lemmas: null.StringFrom("собака")},
+		{TgChatID: 1, TgID: 11, CreatedAt: now, Text: "big dog", Lemmas: null.StringFrom("большой собака")},
+		{TgChatID: 1, TgID: 12, CreatedAt: now, Text: "big hound", Lemmas: null.StringFrom("большой пёс")},
+		{TgChatID: 1, TgID: 13, CreatedAt: now, Text: "not indexed"},
+		{TgChatID: 2, TgID: 14, CreatedAt: now, Text: "other chat", Lemmas: null.StringFrom("собака")},
+	})
+	require.NoError(t, err)
+
+	input := MessagesGetInput{TgChatID: 1, Limit: 2}
+	terms := [][]string{{"большой"}, {"пёс", "собака"}}
+
+	count, err := r.MessagesSearchCount(ctx, &input, terms)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	found, err := r.MessagesSearch(ctx, &input, terms, 1)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 11, found[0].TgID)
+	assert.False(t, found[0].Lemmas.Valid)
+
+	// Lemmas are kept, if upserted message is not indexed, and are reindexed on change
+	err = r.MessagesUpsert(ctx, message_service.Messages{
+		{TgChatID: 1, TgID: 11, CreatedAt: now, Text: "big dog"},
+		{TgChatID: 1, TgID: 12, CreatedAt: now, Text: "cat", Lemmas: null.StringFrom("кот")},
+	})
+	require.NoError(t, err)
+
+	found, err = r.MessagesSearch(ctx, &input, terms, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 11, found[0].TgID)
+
+	notIndexed, err := r.MessagesNotIndexedGet(ctx, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, notIndexed, 1)
+	assert.Equal(t, "not indexed", notIndexed[0].Text)
+
+	notIndexed[0].Lemmas = null.StringFrom("большой собака")
+	err = r.MessagesLemmasUpdate(ctx, notIndexed)
+	require.NoError(t, err)
+
+	found, err = r.MessagesSearch(ctx, &input, terms, 0)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, 13, found[0].TgID)
+	assert.Equal(t, 11, found[1].TgID)
+
+	notIndexed, err = r.MessagesNotIndexedGet(ctx, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, notIndexed)
+}
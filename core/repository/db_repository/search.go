@@ -0,0 +1,150 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// messagesSearchMigrations create full-text index over lemmas of messages.
+// Index is external content one, so lemmas are stored once, triggers keep index in sync with messages.
+// Messages without lemmas are not indexed.
+var messagesSearchMigrations = []string{ //nolint: gochecknoglobals // FIXME
+	`CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(lemmas, content='messages', content_rowid='id')`,
+	`CREATE TRIGGER IF NOT EXISTS messages_fts_insert AFTER INSERT ON messages
+	WHEN new.lemmas IS NOT NULL BEGIN
+		INSERT INTO messages_fts(rowid, lemmas) VALUES (new.id, new.lemmas);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS messages_fts_delete AFTER DELETE ON messages
+	WHEN old.lemmas IS NOT NULL BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, lemmas) VALUES ('delete', old.id, old.lemmas);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS messages_fts_update AFTER UPDATE OF lemmas ON messages BEGIN
+		INSERT INTO messages_fts(messages_fts, rowid, lemmas)
+			SELECT 'delete', old.id, old.lemmas WHERE old.lemmas IS NOT NULL;
+		INSERT INTO messages_fts(rowid, lemmas)
+			SELECT new.id, new.lemmas WHERE new.lemmas IS NOT NULL;
+	END`,
+}
+
+func migrateMessagesSearch(ctx context.Context, db *gorm.DB) error {
+	for _, migration := range messagesSearchMigrations {
+		err := db.WithContext(ctx).Exec(migration).Error
+		if err != nil {
+			return errors.Wrap(err, "failed to create search index")
+		}
+	}
+
+	return nil
+}
+
+// MessagesNotIndexedGet
+// Returns messages of chat, which were stored before lemmas were stored.
+func (r *Repository) MessagesNotIndexedGet(
+	ctx context.Context,
+	tgChatID int64,
+	limit int,
+) (message_service.Messages, error) {
+	var messages message_service.Messages
+
+	err := r.db.WithContext(ctx).
+		Select("id", "text").
+		Where("tg_chat_id = ? AND lemmas IS NULL", tgChatID).
+		Limit(limit).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find not indexed messages")
+	}
+
+	return messages, nil
+}
+
+// MessagesLemmasUpdate
+// Saves lemmas of stored messages, so they are added to search index.
+func (r *Repository) MessagesLemmasUpdate(ctx context.Context, messages message_service.Messages) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, message := range messages {
+			err := tx.Model(&message_service.Message{}).
+				Where("id = ?", message.ID).
+				Update("lemmas", message.Lemmas).
+				Error
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to update lemmas")
+	}
+
+	return nil
+}
+
+// searchMatch
+// Compiles full-text query, which matches messages with any lemma of each term.
+func searchMatch(terms [][]string) string {
+	groups := make([]string, 0, len(terms))
+
+	for _, term := range terms {
+		lemmas := make([]string, 0, len(term))
+		for _, lemma := range term {
+			lemmas = append(lemmas, `"`+strings.ReplaceAll(lemma, `"`, `""`)+`"`)
+		}
+
+		groups = append(groups, "("+strings.Join(lemmas, " OR ")+")")
+	}
+
+	return strings.Join(groups, " AND ")
+}
+
+func (r *Repository) messagesSearchQuery(ctx context.Context, input *MessagesGetInput, terms [][]string) *gorm.DB {
+	return r.messagesQuery(ctx, input).
+		Where("id IN (SELECT rowid FROM messages_fts WHERE messages_fts MATCH ?)", searchMatch(terms))
+}
+
+// MessagesSearch
+// Returns messages, which contain every term, ordered from newest to oldest.
+// Term is matched by any of its lemmas, offset and Limit of input are used for pagination.
+func (r *Repository) MessagesSearch(
+	ctx context.Context,
+	input *MessagesGetInput,
+	terms [][]string,
+	offset int,
+) (message_service.Messages, error) {
+	var messages message_service.Messages
+
+	query := r.messagesSearchQuery(ctx, input, terms).
+		Omit("lemmas").
+		Order("tg_id DESC").
+		Offset(offset)
+
+	if input.Limit > 0 {
+		query = query.Limit(input.Limit)
+	}
+
+	err := query.Find(&messages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search messages")
+	}
+
+	return messages, nil
+}
+
+// MessagesSearchCount
+// Returns amount of messages, which MessagesSearch would return without limit.
+func (r *Repository) MessagesSearchCount(ctx context.Context, input *MessagesGetInput, terms [][]string) (int, error) {
+	var count int64
+
+	err := r.messagesSearchQuery(ctx, input, terms).Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count found messages")
+	}
+
+	return int(count), nil
+}
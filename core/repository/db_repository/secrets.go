@@ -0,0 +1,33 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+// SecretGetOrCreate
+// Returns stored secret, value is stored if there is no secret with such name yet.
+func (r *Repository) SecretGetOrCreate(ctx context.Context, name string, value string) (string, error) {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&message_service.Secret{Name: name, Value: value}).
+		Error
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create secret")
+	}
+
+	var secret message_service.Secret
+
+	err = r.db.WithContext(ctx).
+		Where("name = ?", name).
+		First(&secret).
+		Error
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get secret")
+	}
+
+	return secret.Value, nil
+}
@@ -0,0 +1,38 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SyncStateGet
+// Returns empty state if chat was never synced.
+func (r *Repository) SyncStateGet(ctx context.Context, tgChatID int64) (message_service.SyncState, error) {
+	state := message_service.SyncState{TgChatID: tgChatID}
+
+	err := r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Take(&state).
+		Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return message_service.SyncState{}, errors.Wrap(err, "failed to get sync state")
+	}
+
+	return state, nil
+}
+
+func (r *Repository) SyncStateUpsert(ctx context.Context, state *message_service.SyncState) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(state).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert sync state")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+func (r *Repository) UsersInChatUpsert(ctx context.Context, users message_service.UsersInChat) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		CreateInBatches(users, upsertBatchSize).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert users in chat")
+	}
+
+	return nil
+}
+
+func (r *Repository) UsersInChatGet(ctx context.Context, tgChatID int64) (message_service.UsersInChat, error) {
+	var users message_service.UsersInChat
+
+	err := r.db.WithContext(ctx).
+		Where("tg_chat_id = ?", tgChatID).
+		Find(&users).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find users in chat")
+	}
+
+	return users, nil
+}
+
+// UsersInChatInsertMissing
+// Inserts only users, which are not stored yet, so known names and statuses are not overwritten.
+func (r *Repository) UsersInChatInsertMissing(ctx context.Context, users message_service.UsersInChat) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(users, upsertBatchSize).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to insert users in chat")
+	}
+
+	return nil
+}
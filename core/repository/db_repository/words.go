@@ -0,0 +1,59 @@
+package db_repository
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+func (r *Repository) StopWordUpsert(ctx context.Context, stopWord *message_service.StopWord) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(stopWord).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert stop word")
+	}
+
+	return nil
+}
+
+func (r *Repository) LemmaOverrideUpsert(ctx context.Context, override *message_service.LemmaOverride) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(override).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "failed to upsert lemma override")
+	}
+
+	return nil
+}
+
+// WordsConfigGet
+// Returns global and chat words config, global entries go first, so chat ones can override them.
+func (r *Repository) WordsConfigGet(ctx context.Context, tgChatID int64) (message_service.WordsConfig, error) {
+	var config message_service.WordsConfig
+
+	err := r.db.WithContext(ctx).
+		Where("tg_chat_id IN ?", []int64{message_service.GlobalTgChatID, tgChatID}).
+		Order("tg_chat_id = 0 DESC, word").
+		Find(&config.StopWords).
+		Error
+	if err != nil {
+		return message_service.WordsConfig{}, errors.Wrap(err, "failed to get stop words")
+	}
+
+	err = r.db.WithContext(ctx).
+		Where("tg_chat_id IN ?", []int64{message_service.GlobalTgChatID, tgChatID}).
+		Order("tg_chat_id = 0 DESC, lemma").
+		Find(&config.LemmaOverrides).
+		Error
+	if err != nil {
+		return message_service.WordsConfig{}, errors.Wrap(err, "failed to get lemma overrides")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,112 @@
+package analitics
+
+import (
+	"context"
+	"fmt"
+	"fun_telegram/core/shared"
+	"fun_telegram/core/supplier/ds_supplier"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const peakHoursLimit = 20
+
+// getActivityHeatmap
+// Draws messages count by weekday and hour of day.
+func (r *Service) getActivityHeatmap(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseChatInput,
+) {
+	output := statsReport{
+		repostImage: File{
+			Name:      "ActivityByWeekdayAndHour",
+			Extension: "jpeg",
+		},
+	}
+
+	counts := input.Storage.Messages.GroupByWeekdayHour(shared.TZTime)
+
+	// All cells are added, so rows and columns of heatmap are ordered by weekday and hour.
+	edges := make([]ds_supplier.GraphEdge, 0, len(counts)*len(counts[0]))
+	for weekdayIdx, hours := range counts {
+		weekday := time.Weekday((weekdayIdx + 1) % 7).String()[:3]
+		for hour, count := range hours {
+			edges = append(edges, ds_supplier.GraphEdge{
+				First:  weekday,
+				Second: fmt.Sprintf("%02d", hour),
+				Weight: float64(count),
+			})
+		}
+	}
+
+	jpgImg, err := r.drawer.DrawGraphAsHeatpmap(ctx, &ds_supplier.DrawGraphInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:   fmt.Sprintf("Messages by weekday and hour, %s", shared.TZ),
+			XLabel:  "Hour",
+			YLabel:  "Weekday",
+			FigSize: []int{16, 7},
+		},
+		Edges: edges,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw heatmap")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+// getUsersPeakHours
+// Draws most active users with hour of day, in which they write most of messages.
+func (r *Service) getUsersPeakHours(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseChatInput,
+) {
+	output := statsReport{
+		repostImage: File{
+			Name:      "UsersPeakHours",
+			Extension: "jpeg",
+		},
+	}
+
+	users := input.Storage.Messages.GroupByPeakHour(shared.TZTime)
+	users.SortByMessagesCount()
+
+	if len(users) > peakHoursLimit {
+		users = users[:peakHoursLimit]
+	}
+
+	userToCount := make(map[string]float64, len(users))
+	for _, user := range users {
+		label := fmt.Sprintf(
+			"%s, %02d:00",
+			input.Storage.UsersNameGetter.GetName(user.TgUserID),
+			user.PeakHour,
+		)
+		userToCount[label] = float64(user.PeakMessagesCount)
+	}
+
+	jpgImg, err := r.drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:  fmt.Sprintf("Peak hours of most active users, %s", shared.TZ),
+			XLabel: "User, peak hour",
+			YLabel: "Messages written in peak hour",
+		},
+		Values: userToCount,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
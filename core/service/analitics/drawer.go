@@ -0,0 +1,57 @@
+package analitics
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// FallbackDrawer
+// Draws with main drawer, fallback one is used on each failure of main, e.g. if ds supplier is down.
+type FallbackDrawer struct {
+	main     Drawer
+	fallback Drawer
+}
+
+func NewFallbackDrawer(main Drawer, fallback Drawer) *FallbackDrawer {
+	return &FallbackDrawer{main: main, fallback: fallback}
+}
+
+func drawWithFallback[T any](
+	ctx context.Context,
+	input T,
+	main func(ctx context.Context, input T) ([]byte, error),
+	fallback func(ctx context.Context, input T) ([]byte, error),
+) ([]byte, error) {
+	image, err := main(ctx, input)
+	if err == nil {
+		return image, nil
+	}
+
+	zerolog.Ctx(ctx).Warn().Stack().Err(err).Msg("drawer.failed.falling.back")
+
+	image, err = fallback(ctx, input)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to draw with fallback drawer")
+	}
+
+	return image, nil
+}
+
+func (r *FallbackDrawer) DrawBar(ctx context.Context, input *ds_supplier.DrawBarInput) ([]byte, error) {
+	return drawWithFallback(ctx, input, r.main.DrawBar, r.fallback.DrawBar)
+}
+
+func (r *FallbackDrawer) DrawTimeseries(ctx context.Context, input *ds_supplier.DrawTimeseriesInput) ([]byte, error) {
+	return drawWithFallback(ctx, input, r.main.DrawTimeseries, r.fallback.DrawTimeseries)
+}
+
+func (r *FallbackDrawer) DrawGraph(ctx context.Context, input *ds_supplier.DrawGraphInput) ([]byte, error) {
+	return drawWithFallback(ctx, input, r.main.DrawGraph, r.fallback.DrawGraph)
+}
+
+func (r *FallbackDrawer) DrawGraphAsHeatpmap(ctx context.Context, input *ds_supplier.DrawGraphInput) ([]byte, error) {
+	return drawWithFallback(ctx, input, r.main.DrawGraphAsHeatpmap, r.fallback.DrawGraphAsHeatpmap)
+}
@@ -0,0 +1,67 @@
+package analitics
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+type drawerMock struct {
+	image []byte
+	err   error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (r *drawerMock) draw() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls++
+
+	return r.image, r.err
+}
+
+func (r *drawerMock) DrawBar(context.Context, *ds_supplier.DrawBarInput) ([]byte, error) {
+	return r.draw()
+}
+
+func (r *drawerMock) DrawTimeseries(context.Context, *ds_supplier.DrawTimeseriesInput) ([]byte, error) {
+	return r.draw()
+}
+
+func (r *drawerMock) DrawGraph(context.Context, *ds_supplier.DrawGraphInput) ([]byte, error) {
+	return r.draw()
+}
+
+func (r *drawerMock) DrawGraphAsHeatpmap(context.Context, *ds_supplier.DrawGraphInput) ([]byte, error) {
+	return r.draw()
+}
+
+func TestUnit_Analitics_FallbackDrawer_Ok(t *testing.T) {
+	t.Parallel()
+
+	ctx := test_utils.GetLoggedContext()
+	main := &drawerMock{image: []byte("main")}
+	fallback := &drawerMock{image: []byte("fallback")}
+	drawer := NewFallbackDrawer(main, fallback)
+
+	image, err := drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("main"), image)
+
+	main.err = errors.New("ds is down")
+
+	image, err = drawer.DrawGraph(ctx, &ds_supplier.DrawGraphInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("fallback"), image)
+	assert.Equal(t, 2, main.calls)
+	assert.Equal(t, 1, fallback.calls)
+}
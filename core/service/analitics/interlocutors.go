@@ -0,0 +1,129 @@
+package analitics
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/pkg/errors"
+)
+
+const interlocutorsLimit = 15
+
+// getInterlocutorsEdges
+// Returns reply edges between most chatty users, each user has at most perUserLimit strongest edges.
+func getInterlocutorsEdges(
+	input *AnaliseChatInput,
+	perUserLimit int,
+	minWeight uint64,
+) []ds_supplier.GraphEdge {
+	userToCountArray := input.Storage.Messages.GroupByUserID()
+	userToCountArray.SortByWordsCount(false)
+
+	chattyUsers := mapset.NewSet[int64]()
+	for _, user := range userToCountArray[:min(interlocutorsLimit, len(userToCountArray))] {
+		chattyUsers.Add(user.TgUserID)
+	}
+
+	replies := input.Storage.Messages.GroupByReplies()
+	replies.SortByMessagesCount()
+
+	edges := make([]ds_supplier.GraphEdge, 0, interlocutorsLimit*perUserLimit)
+	userEdgesCount := make(map[int64]int, interlocutorsLimit)
+
+	for _, reply := range replies {
+		if reply.MessagesCount < minWeight ||
+			!chattyUsers.Contains(reply.TgUserID) ||
+			!chattyUsers.Contains(reply.ReplyToTgUserID) ||
+			userEdgesCount[reply.TgUserID] >= perUserLimit {
+			continue
+		}
+
+		userEdgesCount[reply.TgUserID]++
+
+		edges = append(edges, ds_supplier.GraphEdge{
+			First:  input.Storage.UsersNameGetter.GetName(reply.TgUserID),
+			Second: input.Storage.UsersNameGetter.GetName(reply.ReplyToTgUserID),
+			Weight: float64(reply.MessagesCount),
+		})
+	}
+
+	return edges
+}
+
+func (r *Service) getInterlocutorsGraph(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseChatInput,
+) {
+	output := statsReport{
+		repostImage: File{
+			Name:      "Interlocutors",
+			Extension: "jpeg",
+		},
+	}
+
+	edges := getInterlocutorsEdges(input, 3, 3)
+	if len(edges) == 0 {
+		output.err = errors.New("no edges of graph")
+		statsReportChan <- output
+
+		return
+	}
+
+	jpgImg, err := r.drawer.DrawGraph(ctx, &ds_supplier.DrawGraphInput{
+		DrawInput:     ds_supplier.DrawInput{Title: "Interlocutors"},
+		Edges:         edges,
+		Layout:        "neato",
+		WeightedEdges: true,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw graph")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+func (r *Service) getInterlocutorsHeatmap(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseChatInput,
+) {
+	output := statsReport{
+		repostImage: File{
+			Name:      "InterlocutorsAsHeatmap",
+			Extension: "jpeg",
+		},
+	}
+
+	edges := getInterlocutorsEdges(input, interlocutorsLimit, 0)
+	if len(edges) == 0 {
+		output.err = errors.New("no edges of graph")
+		statsReportChan <- output
+
+		return
+	}
+
+	jpgImg, err := r.drawer.DrawGraphAsHeatpmap(ctx, &ds_supplier.DrawGraphInput{
+		WeightedEdges: false,
+		DrawInput: ds_supplier.DrawInput{
+			Title:  "Interlocutors",
+			XLabel: "User replied by",
+			YLabel: "User replies to",
+		},
+		Edges: edges,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw graph")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
@@ -0,0 +1,288 @@
+package analitics
+
+import (
+	"context"
+	"fmt"
+	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/supplier/ds_supplier"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	chatTopWordsLimit         = 30
+	distinctiveWordsUserLimit = 10
+	distinctiveWordsLimit     = 3
+)
+
+type WordCount struct {
+	Word  string
+	Count uint64
+}
+
+type UserWords struct {
+	TgUserID int64
+	Words    []WordCount
+}
+
+// countLemmasByUser
+// Counts lemmas of words written by each user, service words are skipped.
+func (r *Service) countLemmasByUser(
+	filter *wordsFilter,
+	messages message_service.Messages,
+) map[int64]map[string]uint64 {
+	counts := make(map[int64]map[string]uint64, 100)
+
+	for _, message := range messages {
+		userCounts, ok := counts[message.TgUserID]
+		if !ok {
+			userCounts = make(map[string]uint64, 100)
+			counts[message.TgUserID] = userCounts
+		}
+
+		for _, word := range strings.Fields(message.Text) {
+			lemma, ok := r.filterAndLemma(filter, word)
+			if !ok {
+				continue
+			}
+
+			userCounts[lemma]++
+		}
+	}
+
+	return counts
+}
+
+// sortWordCounts
+// Returns words sorted by count descending, words with same count are sorted alphabetically.
+func sortWordCounts(counts map[string]uint64) []WordCount {
+	words := make([]WordCount, 0, len(counts))
+	for word, count := range counts {
+		words = append(words, WordCount{Word: word, Count: count})
+	}
+
+	slices.SortFunc(words, func(a, b WordCount) int {
+		if a.Count == b.Count {
+			return strings.Compare(a.Word, b.Word)
+		}
+
+		if a.Count > b.Count {
+			return -1
+		}
+
+		return 1
+	})
+
+	return words
+}
+
+// sumWordCounts
+// Sums counts of words of all users.
+func sumWordCounts(userCounts map[int64]map[string]uint64) map[string]uint64 {
+	counts := make(map[string]uint64, 1000)
+
+	for _, words := range userCounts {
+		for word, count := range words {
+			counts[word] += count
+		}
+	}
+
+	return counts
+}
+
+// distinctiveWords
+// Returns words with the highest TF-IDF of each given user, where document is all words of one user.
+// Words used only once by user are skipped, as they are mostly noise.
+func distinctiveWords(userCounts map[int64]map[string]uint64, userIDs []int64, limit int) []UserWords {
+	usersWithWord := make(map[string]int, 1000)
+	for _, words := range userCounts {
+		for word := range words {
+			usersWithWord[word]++
+		}
+	}
+
+	type scoredWord struct {
+		WordCount
+		score float64
+	}
+
+	output := make([]UserWords, 0, len(userIDs))
+
+	for _, userID := range userIDs {
+		var total uint64
+		for _, count := range userCounts[userID] {
+			total += count
+		}
+
+		scored := make([]scoredWord, 0, len(userCounts[userID]))
+
+		for word, count := range userCounts[userID] {
+			if count < 2 {
+				continue
+			}
+
+			idf := math.Log(float64(len(userCounts)) / float64(usersWithWord[word]))
+			scored = append(scored, scoredWord{
+				WordCount: WordCount{Word: word, Count: count},
+				score:     float64(count) / float64(total) * idf,
+			})
+		}
+
+		slices.SortFunc(scored, func(a, b scoredWord) int {
+			if a.score == b.score {
+				return strings.Compare(a.Word, b.Word)
+			}
+
+			if a.score > b.score {
+				return -1
+			}
+
+			return 1
+		})
+
+		words := make([]WordCount, 0, limit)
+		for _, word := range scored[:min(limit, len(scored))] {
+			if word.score <= 0 {
+				break
+			}
+
+			words = append(words, word.WordCount)
+		}
+
+		output = append(output, UserWords{TgUserID: userID, Words: words})
+	}
+
+	return output
+}
+
+// getDistinctiveWords
+// Returns distinctive words of most chatty users.
+func getDistinctiveWords(input *AnaliseChatInput, userCounts map[int64]map[string]uint64) []UserWords {
+	userToCountArray := input.Storage.Messages.GroupByUserID()
+	userToCountArray.SortByWordsCount(false)
+
+	userIDs := make([]int64, 0, distinctiveWordsUserLimit)
+	for _, user := range userToCountArray[:min(distinctiveWordsUserLimit, len(userToCountArray))] {
+		userIDs = append(userIDs, user.TgUserID)
+	}
+
+	return distinctiveWords(userCounts, userIDs, distinctiveWordsLimit)
+}
+
+func (r *Service) getTopWords(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	userCounts map[int64]map[string]uint64,
+) {
+	output := statsReport{
+		repostImage: File{Name: "TopWords", Extension: "jpeg"},
+	}
+
+	words := sortWordCounts(sumWordCounts(userCounts))
+	if len(words) == 0 {
+		statsReportChan <- output
+
+		return
+	}
+
+	wordToCount := make(map[string]float64, chatTopWordsLimit)
+	for _, word := range words[:min(chatTopWordsLimit, len(words))] {
+		wordToCount[word.Word] = float64(word.Count)
+	}
+
+	jpgImg, err := r.drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:  "Most used words",
+			XLabel: "Word",
+			YLabel: "Times used",
+		},
+		Values: wordToCount,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+// getDistinctiveWordsHeatmap
+// Draws how often chatty users use their distinctive words.
+func (r *Service) getDistinctiveWordsHeatmap(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseChatInput,
+	usersWords []UserWords,
+) {
+	output := statsReport{
+		repostImage: File{Name: "DistinctiveWords", Extension: "jpeg"},
+	}
+
+	edges := make([]ds_supplier.GraphEdge, 0, len(usersWords)*distinctiveWordsLimit)
+
+	for _, userWords := range usersWords {
+		for _, word := range userWords.Words {
+			edges = append(edges, ds_supplier.GraphEdge{
+				First:  input.Storage.UsersNameGetter.GetName(userWords.TgUserID),
+				Second: word.Word,
+				Weight: float64(word.Count),
+			})
+		}
+	}
+
+	if len(edges) == 0 {
+		statsReportChan <- output
+
+		return
+	}
+
+	jpgImg, err := r.drawer.DrawGraphAsHeatpmap(ctx, &ds_supplier.DrawGraphInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:   "Distinctive words",
+			XLabel:  "Word",
+			YLabel:  "User",
+			FigSize: []int{16, 8},
+		},
+		Edges: edges,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw heatmap")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+// formatUsersWords
+// Formats words of users as text table, one user per line.
+func formatUsersWords(input *AnaliseChatInput, usersWords []UserWords) []string {
+	lines := make([]string, 0, len(usersWords))
+
+	for _, userWords := range usersWords {
+		if len(userWords.Words) == 0 {
+			continue
+		}
+
+		words := make([]string, 0, len(userWords.Words))
+		for _, word := range userWords.Words {
+			words = append(words, word.Word)
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"%s: %s",
+			input.Storage.UsersNameGetter.GetName(userWords.TgUserID),
+			strings.Join(words, ", "),
+		))
+	}
+
+	return lines
+}
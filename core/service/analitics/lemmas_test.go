@@ -0,0 +1,37 @@
+package analitics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Analitics_DistinctiveWords_Ok(t *testing.T) {
+	t.Parallel()
+
+	userCounts := map[int64]map[string]uint64{
+		10: {"кот": 10, "работа": 5, "привет": 3, "редкий": 1},
+		20: {"собака": 4, "работа": 5, "привет": 3},
+		30: {"работа": 2, "привет": 2},
+	}
+
+	usersWords := distinctiveWords(userCounts, []int64{10, 20, 30}, 2)
+
+	require.Len(t, usersWords, 3)
+	// Words used by every user are not distinctive.
+	assert.Equal(t, []WordCount{{Word: "кот", Count: 10}}, usersWords[0].Words)
+	assert.Equal(t, []WordCount{{Word: "собака", Count: 4}}, usersWords[1].Words)
+	assert.Empty(t, usersWords[2].Words)
+}
+
+func TestUnit_Analitics_SortWordCounts_Ok(t *testing.T) {
+	t.Parallel()
+
+	words := sortWordCounts(sumWordCounts(map[int64]map[string]uint64{
+		10: {"кот": 1, "дом": 2},
+		20: {"кот": 2, "сад": 3},
+	}))
+
+	assert.Equal(t, []WordCount{{Word: "кот", Count: 3}, {Word: "сад", Count: 3}, {Word: "дом", Count: 2}}, words)
+}
@@ -0,0 +1,83 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"math"
+	"slices"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+type FindRelevantInput struct {
+	Query    string
+	Limit    int
+	Messages message_service.Messages
+	// WordsConfig is applied to lemmas of query and messages, so stop words of chat are not searched.
+	WordsConfig message_service.WordsConfig
+}
+
+type relevantMessage struct {
+	message message_service.Message
+	score   float64
+}
+
+// FindRelevantMessages
+// Returns messages, which share lemmas with query, ordered by relevance.
+// Rare lemmas weigh more, messages without any lemma of query are skipped.
+func (r *Service) FindRelevantMessages(input *FindRelevantInput) message_service.Messages {
+	filter := newWordsFilter(&input.WordsConfig)
+
+	queryLemmas := mapset.NewThreadUnsafeSet(r.lemmas(filter, input.Query)...)
+	if queryLemmas.Cardinality() == 0 {
+		return nil
+	}
+
+	messagesLemmas := make([]mapset.Set[string], len(input.Messages))
+	documentFrequency := make(map[string]int, queryLemmas.Cardinality())
+
+	for idx, message := range input.Messages {
+		messagesLemmas[idx] = mapset.NewThreadUnsafeSet(r.lemmas(filter, message.Text)...)
+
+		for lemma := range queryLemmas.Iter() {
+			if messagesLemmas[idx].Contains(lemma) {
+				documentFrequency[lemma]++
+			}
+		}
+	}
+
+	relevant := make([]relevantMessage, 0, input.Limit)
+
+	for idx, message := range input.Messages {
+		var score float64
+
+		for lemma := range documentFrequency {
+			if messagesLemmas[idx].Contains(lemma) {
+				score += math.Log(float64(len(input.Messages)+1) / float64(documentFrequency[lemma]))
+			}
+		}
+
+		if score > 0 {
+			relevant = append(relevant, relevantMessage{message: message, score: score})
+		}
+	}
+
+	slices.SortStableFunc(relevant, func(a, b relevantMessage) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+
+			return 1
+		}
+
+		// Newer messages are more likely to be actual
+		return b.message.TgID - a.message.TgID
+	})
+
+	output := make(message_service.Messages, 0, min(input.Limit, len(relevant)))
+	for _, message := range relevant[:min(input.Limit, len(relevant))] {
+		output = append(output, message.message)
+	}
+
+	return output
+}
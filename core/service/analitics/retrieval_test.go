@@ -0,0 +1,39 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Analitics_FindRelevantMessages_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, nil)
+	require.NoError(t, err)
+
+	messages := message_service.Messages{
+		{TgID: 1, Text: "обсуждаем релиз и дату релиза"},
+		{TgID: 2, Text: "кот спит на диване"},
+		{TgID: 3, Text: "решили перенести релиз на пятницу"},
+		{TgID: 4, Text: "пятница будет хорошей"},
+		{TgID: 5, Text: "релиз"},
+	}
+
+	relevant := service.FindRelevantMessages(&FindRelevantInput{
+		Query:    "что решили про релиз?",
+		Limit:    3,
+		Messages: messages,
+	})
+
+	tgIDs := make([]int, 0, len(relevant))
+	for _, message := range relevant {
+		tgIDs = append(tgIDs, message.TgID)
+	}
+
+	// Message with both lemmas goes first, then newer messages with one lemma.
+	assert.Equal(t, []int{3, 5, 1}, tgIDs)
+	assert.Nil(t, service.FindRelevantMessages(&FindRelevantInput{Query: "что это", Limit: 3, Messages: messages}))
+}
@@ -0,0 +1,88 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"slices"
+	"strings"
+
+	"github.com/guregu/null/v5"
+	"github.com/pkg/errors"
+)
+
+var ErrEmptyQuery = errors.New("query has no words to search, only stop words or too short ones")
+
+// indexLemma
+// Returns lemma of word, which is stored for search, stop words and lemma overrides are not applied.
+func (r *Service) indexLemma(word string) (string, bool) {
+	word = NormalizeWord(word)
+	if word == "" || len(word) < 3 {
+		return "", false
+	}
+
+	return r.lemmatizer.Lemma(word), true
+}
+
+// IndexMessage
+// Stores lemmas of words of message, so it can be found by search without lemmatization.
+func (r *Service) IndexMessage(m *message_service.Message) {
+	words := strings.Fields(m.Text)
+	lemmas := make([]string, 0, len(words))
+
+	for _, word := range words {
+		lemma, ok := r.indexLemma(word)
+		if ok {
+			lemmas = append(lemmas, lemma)
+		}
+	}
+
+	m.Lemmas = null.StringFrom(strings.Join(lemmas, " "))
+}
+
+// IndexMessages
+// Indexes messages, which were stored before lemmas were stored.
+func (r *Service) IndexMessages(messages message_service.Messages) {
+	for idx := range messages {
+		r.IndexMessage(&messages[idx])
+	}
+}
+
+// SearchTerms
+// Returns terms, which stored lemmas of message must match to match every word of query.
+// Term is matched by any of its lemmas, so stop words and lemma overrides of chat are applied to stored lemmas.
+func (r *Service) SearchTerms(query string, config *message_service.WordsConfig) ([][]string, error) {
+	filter := newWordsFilter(config)
+	terms := make([][]string, 0)
+
+	for _, word := range strings.Fields(query) {
+		lemma, ok := r.filterAndLemma(filter, word)
+		if !ok {
+			continue
+		}
+
+		// Stored lemma is matched, if it is replaced by the same lemma as word of query
+		term := make([]string, 0, 1)
+		if _, overridden := filter.lemmaToLemma[lemma]; !overridden && !filter.stopWords.Contains(lemma) {
+			term = append(term, lemma)
+		}
+
+		for from, to := range filter.lemmaToLemma {
+			if to == lemma && !filter.stopWords.Contains(from) {
+				term = append(term, from)
+			}
+		}
+
+		// Lemma is replaced by other one, which is replaced further, so it is searched as is
+		if len(term) == 0 {
+			term = append(term, lemma)
+		}
+
+		slices.Sort(term)
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	return terms, nil
+}
@@ -0,0 +1,58 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Analitics_IndexMessage_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, nil)
+	require.NoError(t, err)
+
+	message := message_service.Message{Text: "Видел большую собаку, во дворе!"}
+	service.IndexMessage(&message)
+
+	require.True(t, message.Lemmas.Valid)
+	assert.Equal(t, "видеть большой собака во двор", message.Lemmas.String)
+}
+
+func TestUnit_Analitics_SearchTerms_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, nil)
+	require.NoError(t, err)
+
+	terms, err := service.SearchTerms("большие собаки", &message_service.WordsConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"большой"}, {"собака"}}, terms)
+
+	terms, err = service.SearchTerms("собаки", &message_service.WordsConfig{
+		LemmaOverrides: []message_service.LemmaOverride{
+			{Lemma: "пёс", Replacement: "собака"},
+			{Lemma: "псина", Replacement: "собака"},
+		},
+		StopWords: []message_service.StopWord{{Word: "псина"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"пёс", "собака"}}, terms)
+}
+
+func TestUnit_Analitics_SearchTerms_Err(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, nil)
+	require.NoError(t, err)
+
+	_, err = service.SearchTerms("что это", &message_service.WordsConfig{})
+	require.ErrorIs(t, err, ErrEmptyQuery)
+
+	_, err = service.SearchTerms("собака", &message_service.WordsConfig{
+		StopWords: []message_service.StopWord{{Word: "собака"}},
+	})
+	require.ErrorIs(t, err, ErrEmptyQuery)
+}
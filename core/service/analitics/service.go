@@ -14,26 +14,34 @@ import (
 
 	"github.com/aaaton/golem/v4"
 	"github.com/aaaton/golem/v4/dicts/ru"
-	"github.com/dlclark/regexp2"
 	"github.com/pkg/errors"
 )
 
-type Service struct {
-	dsSupplier *ds_supplier.Supplier
-
-	toxicityExp *regexp2.Regexp
-	lemmatizer  *golem.Lemmatizer
+// Drawer
+// Renders charts, implemented by ds_supplier.Supplier and chart_supplier.Supplier.
+type Drawer interface {
+	DrawBar(ctx context.Context, input *ds_supplier.DrawBarInput) ([]byte, error)
+	DrawTimeseries(ctx context.Context, input *ds_supplier.DrawTimeseriesInput) ([]byte, error)
+	DrawGraph(ctx context.Context, input *ds_supplier.DrawGraphInput) ([]byte, error)
+	DrawGraphAsHeatpmap(ctx context.Context, input *ds_supplier.DrawGraphInput) ([]byte, error)
 }
 
-func New(dsSupplier *ds_supplier.Supplier) (*Service, error) {
-	r := Service{dsSupplier: dsSupplier}
+type Service struct {
+	drawer Drawer
 
-	exp := regexp2.MustCompile(
-		`((у|[нз]а|(хитро|не)?вз?[ыьъ]|с[ьъ]|(и|ра)[зс]ъ?|(о[тб]|под)[ьъ]?|(.\B)+?[оаеи])?-?([её]б(?!о[рй])|и[пб][ае][тц]).*?|(н[иеа]|[дп]о|ра[зс]|з?а|с(ме)?|о(т|дно)?|апч)?-?х[уy]([яйиеёю]|ли(?!ган)).*?|(в[зы]|(три|два|четыре)жды|(н|сук)а)?-?[б6]л(я(?!(х|ш[кн]|мб)[ауеыио]).*?|[еэ][дт]ь?)|(ра[сз]|[зн]а|[со]|вы?|п(р[ои]|од)|и[зс]ъ?|[ао]т)?п[иеё]зд.*?|(за)?п[ие]д[аое]?р((ас)?(и(ли)?[нщктл]ь?)?|(о(ч[еи])?)?к|юг)[ауеы]?|манд([ауеы]|ой|[ао]вошь?(е?к[ауе])?|юк(ов|[ауи])?)|муд([аио].*?|е?н([ьюия]|ей))|мля([тд]ь)?|лять|([нз]а|по)х|м[ао]л[ао]фь[яию]|(жоп|чмо|гнид)[а-я]*|г[ао]ндон|[а-я]*(с[рс]ать|хрен|хер|дрист|дроч|минет|говн|шлюх|г[а|о]вн)[а-я]*|мраз(ь|ота)|сук[а-я])|cock|fuck(er|ing)?`, //nolint: lll // as expected
-		0,
-	)
+	toxicityScorer         ToxicityScorer
+	fallbackToxicityScorer ToxicityScorer
+	lemmatizer             *golem.Lemmatizer
 
-	r.toxicityExp = exp
+	defaultWordsFilter *wordsFilter
+}
+
+func New(drawer Drawer, toxicityScorer ToxicityScorer) (*Service, error) {
+	r := Service{
+		drawer:                 drawer,
+		toxicityScorer:         toxicityScorer,
+		fallbackToxicityScorer: NewRegexToxicityScorer(),
+	}
 
 	lemmatizer, err := golem.New(ru.New())
 	if err != nil {
@@ -41,6 +49,7 @@ func New(dsSupplier *ds_supplier.Supplier) (*Service, error) {
 	}
 
 	r.lemmatizer = lemmatizer
+	r.defaultWordsFilter = newWordsFilter(&message_service.WordsConfig{})
 
 	return &r, nil
 }
@@ -59,6 +68,10 @@ func (r *File) Filename() string {
 
 type AnaliseReport struct {
 	Images []File
+	// Aliases maps aliases to real names of users, filled if chat is anonymized.
+	Aliases map[string]string
+	// DistinctiveWords are lines of text table with distinctive words of most chatty users.
+	DistinctiveWords []string
 
 	FirstMessageAt time.Time
 	MessagesCount  int
@@ -113,13 +126,18 @@ func (r *AnaliseReport) appendFromChan(
 func (r *Service) analiseWholeChat(
 	ctx context.Context,
 	input *AnaliseChatInput,
+	filter *wordsFilter,
 ) (AnaliseReport, error) { //nolint: unparam // FIXME
 	report := AnaliseReport{
-		Images:         make([]File, 0, 7),
+		Images:         make([]File, 0, 13),
 		FirstMessageAt: time.Now(),
 		MessagesCount:  len(input.Storage.Messages),
 	}
 
+	userCounts := r.countLemmasByUser(filter, input.Storage.Messages)
+	usersWords := getDistinctiveWords(input, userCounts)
+	report.DistinctiveWords = formatUsersWords(input, usersWords)
+
 	statsReportChan := make(chan statsReport)
 
 	var (
@@ -143,6 +161,24 @@ func (r *Service) analiseWholeChat(
 	wg.Go(func() {
 		r.getMostToxicUsers(ctx, statsReportChan, input)
 	})
+	wg.Go(func() {
+		r.getInterlocutorsGraph(ctx, statsReportChan, input)
+	})
+	wg.Go(func() {
+		r.getInterlocutorsHeatmap(ctx, statsReportChan, input)
+	})
+	wg.Go(func() {
+		r.getActivityHeatmap(ctx, statsReportChan, input)
+	})
+	wg.Go(func() {
+		r.getUsersPeakHours(ctx, statsReportChan, input)
+	})
+	wg.Go(func() {
+		r.getTopWords(ctx, statsReportChan, userCounts)
+	})
+	wg.Go(func() {
+		r.getDistinctiveWordsHeatmap(ctx, statsReportChan, input, usersWords)
+	})
 
 	wg.Wait()
 	close(statsReportChan)
@@ -152,8 +188,10 @@ func (r *Service) analiseWholeChat(
 }
 
 type AnaliseChatInput struct {
-	TgChatID  int64 // TODO to remove
+	TgChatID  int64
 	Anonymize bool
+	// AnonymizeSalt makes aliases impossible to compute from ids of users, must not be empty if Anonymize is set
+	AnonymizeSalt string
 
 	Storage message_service.Storage
 }
@@ -161,11 +199,30 @@ type AnaliseChatInput struct {
 func (r *Service) AnaliseChat(ctx context.Context, input *AnaliseChatInput) (AnaliseReport, error) {
 	zerolog.Ctx(ctx).Info().Msg("compiling.stats.begin")
 
-	report, err := r.analiseWholeChat(ctx, input)
+	input.Storage.Messages.ResolveReplies()
+
+	filter := newWordsFilter(&input.Storage.WordsConfig)
+	r.recountWords(filter, input.Storage.Messages)
+
+	if input.Anonymize {
+		input.Storage.UsersNameGetter = input.Storage.GetAnonymizedNameGetter(
+			input.TgChatID,
+			input.AnonymizeSalt,
+		)
+	}
+
+	report, err := r.analiseWholeChat(ctx, input, filter)
 	if err != nil {
 		return AnaliseReport{}, errors.Wrap(err, "failed to analise chat")
 	}
 
+	if input.Anonymize {
+		report.Aliases = make(map[string]string)
+		for _, alias := range input.Storage.UsersNameGetter.GetAliases() {
+			report.Aliases[alias.Alias] = input.Storage.UsersNameGetter.GetRealNameAndUsername(alias.TgUserID)
+		}
+	}
+
 	slices.SortFunc(report.Images, func(a, b File) int {
 		if a.Name > b.Name {
 			return 1
@@ -9,22 +9,40 @@ func (r *Service) NewStorage() *message_service.Storage {
 	return &message_service.Storage{}
 }
 
+// AppendMessage
+// Counts words of message with default words filter, counts are refreshed with chat filter on analise.
+// Message is indexed for search.
 func (r *Service) AppendMessage(s *message_service.Storage, m *message_service.Message) {
-	words := strings.Fields(m.Text)
+	r.countWords(r.defaultWordsFilter, m)
+	r.IndexMessage(m)
 
-	for _, word := range words {
-		word, ok := r.filterAndLemma(word)
-		if !ok {
-			continue
-		}
+	s.Messages = append(s.Messages, *m)
+}
 
-		m.WordsCount++
+// lemmas
+// Returns lemmas of words of text, which are not filtered out.
+func (r *Service) lemmas(filter *wordsFilter, text string) []string {
+	words := strings.Fields(text)
+	lemmas := make([]string, 0, len(words))
 
-		ok, _ = r.IsToxic(word)
+	for _, word := range words {
+		lemma, ok := r.filterAndLemma(filter, word)
 		if ok {
-			m.ToxicWordsCount++
+			lemmas = append(lemmas, lemma)
 		}
 	}
 
-	s.Messages = append(s.Messages, *m)
+	return lemmas
+}
+
+func (r *Service) countWords(filter *wordsFilter, m *message_service.Message) {
+	m.WordsCount = uint64(len(r.lemmas(filter, m.Text)))
+}
+
+// recountWords
+// Counts words of stored messages again, so changes of words config are applied immediately.
+func (r *Service) recountWords(filter *wordsFilter, messages message_service.Messages) {
+	for idx := range messages {
+		r.countWords(filter, &messages[idx])
+	}
 }
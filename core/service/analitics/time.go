@@ -25,7 +25,7 @@ func (r *Service) getMessagesGroupedByDateByChatID(
 		timeToCount[message.CreatedAt.Format(time.RFC3339)] = float64(message.WordsCount)
 	}
 
-	jpgImg, err := r.dsSupplier.DrawTimeseries(ctx, &ds_supplier.DrawTimeseriesInput{
+	jpgImg, err := r.drawer.DrawTimeseries(ctx, &ds_supplier.DrawTimeseriesInput{
 		DrawInput: ds_supplier.DrawInput{
 			Title:  "Word written by date",
 			XLabel: "Date",
@@ -34,7 +34,7 @@ func (r *Service) getMessagesGroupedByDateByChatID(
 		Values: map[string]map[string]float64{"day": timeToCount},
 	})
 	if err != nil {
-		statsReportResult.err = errors.Wrap(err, "failed to draw image in ds supplier")
+		statsReportResult.err = errors.Wrap(err, "failed to draw image")
 		statsReportChan <- statsReportResult
 
 		return
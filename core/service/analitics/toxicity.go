@@ -2,9 +2,13 @@ package analitics
 
 import (
 	"context"
+	"fun_telegram/core/service/message_service"
 	"fun_telegram/core/supplier/ds_supplier"
+	"slices"
 
+	"github.com/guregu/null/v5"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 )
 
 func (r *Service) getMostToxicUsers(
@@ -31,14 +35,12 @@ func (r *Service) getMostToxicUsers(
 
 	userToCount := make(map[string]float64, limit)
 	for _, message := range userToCountArray {
-		userToCount[input.Storage.UsersNameGetter.GetName(message.TgUserID)] = float64(
-			message.ToxicWordsCount,
-		) / float64(
+		userToCount[input.Storage.UsersNameGetter.GetName(message.TgUserID)] = message.ToxicWords / float64(
 			message.WordsCount,
 		) * 100
 	}
 
-	jpgImg, err := r.dsSupplier.DrawBar(ctx, &ds_supplier.DrawBarInput{
+	jpgImg, err := r.drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{
 		DrawInput: ds_supplier.DrawInput{
 			Title:  "Toxic words percent",
 			XLabel: "User",
@@ -47,7 +49,7 @@ func (r *Service) getMostToxicUsers(
 		Values: userToCount,
 	})
 	if err != nil {
-		output.err = errors.Wrap(err, "failed to draw in ds supplier")
+		output.err = errors.Wrap(err, "failed to draw")
 		statsReportChan <- output
 
 		return
@@ -57,11 +59,72 @@ func (r *Service) getMostToxicUsers(
 	statsReportChan <- output
 }
 
-func (r *Service) IsToxic(word string) (bool, error) {
-	match, err := r.toxicityExp.MatchString(word)
-	if err != nil {
-		return false, errors.WithStack(err)
+// ToxicityScorer
+// Scores toxicity of messages from 0 to 1.
+type ToxicityScorer interface {
+	// Name is stored with scores, it must be changed with any change of scoring, so messages are rescored.
+	Name() string
+	Score(ctx context.Context, inputs []ToxicityInput) ([]float64, error)
+}
+
+type ToxicityInput struct {
+	Text string
+	// Lemmas are filtered lemmas of words of message.
+	Lemmas []string
+}
+
+const toxicityBatchSize = 256
+
+// ScoreToxicity
+// Scores messages without toxicity score or scored by other scorer, returns scored messages.
+// Fallback scorer is used, if main one fails.
+func (r *Service) ScoreToxicity(
+	ctx context.Context,
+	messages message_service.Messages,
+) (message_service.Messages, error) {
+	unscored := make([]int, 0, len(messages))
+
+	for idx, message := range messages {
+		if !message.ToxicityScore.Valid || message.ToxicityScorer != r.toxicityScorer.Name() {
+			unscored = append(unscored, idx)
+		}
+	}
+
+	scored := make(message_service.Messages, 0, len(unscored))
+
+	for batch := range slices.Chunk(unscored, toxicityBatchSize) {
+		inputs := make([]ToxicityInput, 0, len(batch))
+		for _, idx := range batch {
+			inputs = append(inputs, ToxicityInput{
+				Text:   messages[idx].Text,
+				Lemmas: r.lemmas(r.defaultWordsFilter, messages[idx].Text),
+			})
+		}
+
+		scorer := r.toxicityScorer
+
+		scores, err := scorer.Score(ctx, inputs)
+		if err != nil {
+			zerolog.Ctx(ctx).
+				Warn().
+				Stack().
+				Err(err).
+				Msg("toxicity.scorer.failed.falling.back")
+
+			scorer = r.fallbackToxicityScorer
+
+			scores, err = scorer.Score(ctx, inputs)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to score toxicity")
+			}
+		}
+
+		for batchIdx, idx := range batch {
+			messages[idx].ToxicityScore = null.FloatFrom(scores[batchIdx])
+			messages[idx].ToxicityScorer = scorer.Name()
+			scored = append(scored, messages[idx])
+		}
 	}
 
-	return match, nil
+	return scored, nil
 }
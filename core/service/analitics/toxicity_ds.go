@@ -0,0 +1,41 @@
+package analitics
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type ToxicityClassifier interface {
+	ClassifyToxicity(ctx context.Context, texts []string) ([]float64, error)
+}
+
+// DsToxicityScorer
+// Scores messages with toxicity classifier of ds service, texts are classified as is.
+type DsToxicityScorer struct {
+	classifier ToxicityClassifier
+}
+
+func NewDsToxicityScorer(classifier ToxicityClassifier) *DsToxicityScorer {
+	return &DsToxicityScorer{classifier: classifier}
+}
+
+// Name
+// Returns name of scorer, ds model is not versioned, so it is rescored only on change of scorer.
+func (r *DsToxicityScorer) Name() string {
+	return "ds:1"
+}
+
+func (r *DsToxicityScorer) Score(ctx context.Context, inputs []ToxicityInput) ([]float64, error) {
+	texts := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		texts = append(texts, input.Text)
+	}
+
+	scores, err := r.classifier.ClassifyToxicity(ctx, texts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to classify toxicity")
+	}
+
+	return scores, nil
+}
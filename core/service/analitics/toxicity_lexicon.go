@@ -0,0 +1,92 @@
+package analitics
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// lexiconStemMinLen is a minimal length of lexicon entry, which is matched as prefix of lemma.
+// Shorter entries are matched only as whole lemma, otherwise they match too many innocent words.
+const lexiconStemMinLen = 4
+
+// toxicityLexicon maps obscene and insulting stems to their weight from 0 to 1.
+var toxicityLexicon = map[string]float64{ //nolint: gochecknoglobals // constant lexicon
+	// Obscene words
+	"хуй": 1, "хуя": 1, "хуйн": 1, "хуев": 1, "хуёв": 1, "хуел": 1, "охуе": 1, "охуи": 1, "нахуй": 1, "похуй": 1,
+	"пизд": 1, "распизд": 1, "спизд": 1, "запизд": 1,
+	"ебат": 1, "ебан": 1, "ебал": 1, "ебну": 1, "ебуч": 1, "ёбан": 1, "выеб": 1, "заеб": 1, "уеба": 1, "долбоеб": 1,
+	"долбоёб": 1, "разъеб": 1, "съеб": 1,
+	"бляд": 1, "блят": 1, "бля": 1, "сука": 1, "сучк": 1, "сучар": 1,
+	"пидор": 1, "пидар": 1, "педик": 0.9, "гандон": 1, "гондон": 1, "шлюх": 1, "залуп": 1, "манда": 1, "мудак": 1,
+	"мудил": 1, "мудозвон": 1,
+	"fuck": 1, "motherfuck": 1, "cunt": 1, "cock": 0.8, "dick": 0.6,
+
+	// Insults
+	"ублюд": 0.8, "мразь": 0.8, "мразот": 0.8, "гнида": 0.8, "чмо": 0.8, "чмошн": 0.8, "сволоч": 0.7, "тварь": 0.7,
+	"скотин": 0.6, "урод": 0.6, "дебил": 0.6, "идиот": 0.5, "кретин": 0.6, "даун": 0.6, "имбецил": 0.6,
+	"придур": 0.5, "дура": 0.4, "дурак": 0.4, "тупица": 0.5, "тупой": 0.4, "лох": 0.4, "лошар": 0.5, "быдл": 0.6,
+	"говн": 0.7, "дерьм": 0.6, "срать": 0.6, "засран": 0.6, "жопа": 0.5, "задниц": 0.3,
+	"shit": 0.7, "bitch": 0.9, "asshole": 0.9, "idiot": 0.5, "stupid": 0.4, "moron": 0.5, "retard": 0.7,
+
+	// Rude words
+	"заткнуться": 0.4, "отвалить": 0.3, "нахер": 0.5, "похер": 0.4, "хрен": 0.3, "херн": 0.4, "отстой": 0.3,
+	"бесить": 0.2, "ненавидеть": 0.3, "задолбать": 0.3, "козёл": 0.4, "козел": 0.4,
+	"stfu": 0.5, "suck": 0.3, "crap": 0.4, "hate": 0.3,
+}
+
+// LexiconToxicityScorer
+// Scores message as sum of weights of its toxic lemmas divided by amount of lemmas.
+type LexiconToxicityScorer struct {
+	lexicon map[string]float64
+}
+
+func NewLexiconToxicityScorer() *LexiconToxicityScorer {
+	return &LexiconToxicityScorer{lexicon: toxicityLexicon}
+}
+
+// Name
+// Returns name of scorer with version of lexicon.
+func (r *LexiconToxicityScorer) Name() string {
+	return "lexicon:1"
+}
+
+// weight
+// Returns weight of the longest lexicon entry matching lemma.
+func (r *LexiconToxicityScorer) weight(lemma string) float64 {
+	if weight, ok := r.lexicon[lemma]; ok {
+		return weight
+	}
+
+	for prefixLen := len(lemma) - 1; prefixLen > 0; prefixLen-- {
+		prefix := lemma[:prefixLen]
+		if !utf8.ValidString(prefix) || utf8.RuneCountInString(prefix) < lexiconStemMinLen {
+			continue
+		}
+
+		if weight, ok := r.lexicon[prefix]; ok {
+			return weight
+		}
+	}
+
+	return 0
+}
+
+func (r *LexiconToxicityScorer) Score(_ context.Context, inputs []ToxicityInput) ([]float64, error) {
+	scores := make([]float64, len(inputs))
+
+	for idx, input := range inputs {
+		if len(input.Lemmas) == 0 {
+			continue
+		}
+
+		var weights float64
+		for _, lemma := range input.Lemmas {
+			weights += r.weight(strings.ToLower(lemma))
+		}
+
+		scores[idx] = min(1, weights/float64(len(input.Lemmas)))
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,61 @@
+package analitics
+
+import (
+	"context"
+
+	"github.com/dlclark/regexp2"
+	"github.com/pkg/errors"
+)
+
+// RegexToxicityScorer
+// Scores message as share of lemmas matching obscene words regexp.
+type RegexToxicityScorer struct {
+	exp *regexp2.Regexp
+}
+
+func NewRegexToxicityScorer() *RegexToxicityScorer {
+	return &RegexToxicityScorer{exp: regexp2.MustCompile(
+		`((у|[нз]а|(хитро|не)?вз?[ыьъ]|с[ьъ]|(и|ра)[зс]ъ?|(о[тб]|под)[ьъ]?|(.\B)+?[оаеи])?-?([её]б(?!о[рй])|и[пб][ае][тц]).*?|(н[иеа]|[дп]о|ра[зс]|з?а|с(ме)?|о(т|дно)?|апч)?-?х[уy]([яйиеёю]|ли(?!ган)).*?|(в[зы]|(три|два|четыре)жды|(н|сук)а)?-?[б6]л(я(?!(х|ш[кн]|мб)[ауеыио]).*?|[еэ][дт]ь?)|(ра[сз]|[зн]а|[со]|вы?|п(р[ои]|од)|и[зс]ъ?|[ао]т)?п[иеё]зд.*?|(за)?п[ие]д[аое]?р((ас)?(и(ли)?[нщктл]ь?)?|(о(ч[еи])?)?к|юг)[ауеы]?|манд([ауеы]|ой|[ао]вошь?(е?к[ауе])?|юк(ов|[ауи])?)|муд([аио].*?|е?н([ьюия]|ей))|мля([тд]ь)?|лять|([нз]а|по)х|м[ао]л[ао]фь[яию]|(жоп|чмо|гнид)[а-я]*|г[ао]ндон|[а-я]*(с[рс]ать|хрен|хер|дрист|дроч|минет|говн|шлюх|г[а|о]вн)[а-я]*|мраз(ь|ота)|сук[а-я])|cock|fuck(er|ing)?`, //nolint: lll // as expected
+		0,
+	)}
+}
+
+func (r *RegexToxicityScorer) Name() string {
+	return "regex:1"
+}
+
+func (r *RegexToxicityScorer) IsToxic(word string) (bool, error) {
+	match, err := r.exp.MatchString(word)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return match, nil
+}
+
+func (r *RegexToxicityScorer) Score(_ context.Context, inputs []ToxicityInput) ([]float64, error) {
+	scores := make([]float64, len(inputs))
+
+	for idx, input := range inputs {
+		if len(input.Lemmas) == 0 {
+			continue
+		}
+
+		var toxicCount int
+
+		for _, lemma := range input.Lemmas {
+			ok, err := r.IsToxic(lemma)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to match word")
+			}
+
+			if ok {
+				toxicCount++
+			}
+		}
+
+		scores[idx] = float64(toxicCount) / float64(len(input.Lemmas))
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,91 @@
+package analitics
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"testing"
+
+	"github.com/guregu/null/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func TestUnit_Analitics_LexiconToxicityScorer_Ok(t *testing.T) {
+	t.Parallel()
+
+	scores, err := NewLexiconToxicityScorer().Score(test_utils.GetLoggedContext(), []ToxicityInput{
+		{Lemmas: []string{"привет", "как", "дело"}},
+		{Lemmas: []string{"ты", "идиот"}},
+		{Lemmas: []string{"пиздец", "пиздец"}},
+		{Lemmas: []string{"лохматый", "кот"}},
+		{},
+	})
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, []float64{0, 0.25, 1, 0, 0}, scores, 0.001)
+}
+
+func TestUnit_Analitics_RegexToxicityScorer_Ok(t *testing.T) {
+	t.Parallel()
+
+	scores, err := NewRegexToxicityScorer().Score(test_utils.GetLoggedContext(), []ToxicityInput{
+		{Lemmas: []string{"привет", "как", "дело"}},
+		{Lemmas: []string{"сука", "кот"}},
+	})
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, []float64{0, 0.5}, scores, 0.001)
+}
+
+func TestUnit_Analitics_ScoreToxicityRescoresOtherScorer_Ok(t *testing.T) {
+	t.Parallel()
+
+	scorer := NewLexiconToxicityScorer()
+
+	service, err := New(nil, scorer)
+	require.NoError(t, err)
+
+	messages := message_service.Messages{
+		{TgID: 1, Text: "ты идиот"},
+		{TgID: 2, Text: "ты идиот", ToxicityScore: null.FloatFrom(0.9), ToxicityScorer: scorer.Name()},
+		{TgID: 3, Text: "ты идиот", ToxicityScore: null.FloatFrom(0.9), ToxicityScorer: "ds"},
+	}
+
+	scored, err := service.ScoreToxicity(test_utils.GetLoggedContext(), messages)
+	require.NoError(t, err)
+	require.Len(t, scored, 2)
+
+	assert.Equal(t, 1, scored[0].TgID)
+	assert.Equal(t, 3, scored[1].TgID)
+
+	for _, message := range scored {
+		assert.Equal(t, scorer.Name(), message.ToxicityScorer)
+		assert.NotEqual(t, 0.9, message.ToxicityScore.Float64)
+	}
+
+	assert.InDelta(t, 0.9, messages[1].ToxicityScore.Float64, 0.001)
+}
+
+type failingToxicityClassifier struct{}
+
+func (failingToxicityClassifier) ClassifyToxicity(context.Context, []string) ([]float64, error) {
+	return nil, errors.New("ds is unavailable")
+}
+
+func TestUnit_Analitics_ScoreToxicityFallsBackFromDs_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, NewDsToxicityScorer(failingToxicityClassifier{}))
+	require.NoError(t, err)
+
+	scored, err := service.ScoreToxicity(test_utils.GetLoggedContext(), message_service.Messages{
+		{TgID: 1, Text: "пиздец"},
+	})
+	require.NoError(t, err)
+	require.Len(t, scored, 1)
+
+	assert.Equal(t, NewRegexToxicityScorer().Name(), scored[0].ToxicityScorer)
+	assert.Positive(t, scored[0].ToxicityScore.Float64)
+}
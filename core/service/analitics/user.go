@@ -0,0 +1,273 @@
+package analitics
+
+import (
+	"context"
+	"fun_telegram/core/service/message_service"
+	"fun_telegram/core/supplier/ds_supplier"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	userTopWordsLimit = 20
+	userPartnersLimit = 15
+)
+
+type AnaliseUserInput struct {
+	TgChatID int64
+	TgUserID int64
+
+	Storage message_service.Storage
+}
+
+type AnaliseUserReport struct {
+	Images []File
+
+	Name   string
+	Status message_service.MemberStatus
+
+	MessagesCount int
+	WordsCount    uint64
+	// ToxicWords is amount of words weighted by toxicity score of their messages.
+	ToxicWords     float64
+	FirstMessageAt time.Time
+	LastMessageAt  time.Time
+
+	TopWords []WordCount
+}
+
+// ToxicityPercent
+// Returns percent of toxic words among all words of user.
+func (r *AnaliseUserReport) ToxicityPercent() float64 {
+	if r.WordsCount == 0 {
+		return 0
+	}
+
+	return r.ToxicWords / float64(r.WordsCount) * 100
+}
+
+// AnaliseUser
+// Compiles personal report of user from messages of chat.
+func (r *Service) AnaliseUser(ctx context.Context, input *AnaliseUserInput) (AnaliseUserReport, error) {
+	zerolog.Ctx(ctx).Info().Int64("tg_user_id", input.TgUserID).Msg("compiling.user.stats.begin")
+
+	input.Storage.Messages.ResolveReplies()
+
+	filter := newWordsFilter(&input.Storage.WordsConfig)
+	r.recountWords(filter, input.Storage.Messages)
+
+	report := AnaliseUserReport{
+		Images: make([]File, 0, 3),
+		Name:   input.Storage.UsersNameGetter.GetNameAndUsername(input.TgUserID),
+		Status: message_service.Unknown,
+	}
+
+	for _, user := range input.Storage.Users {
+		if user.TgID == input.TgUserID {
+			report.Status = user.Status
+			break
+		}
+	}
+
+	userMessages := make(message_service.Messages, 0, 100)
+
+	for _, message := range input.Storage.Messages {
+		if message.TgUserID != input.TgUserID {
+			continue
+		}
+
+		userMessages = append(userMessages, message)
+		report.WordsCount += message.WordsCount
+		report.ToxicWords += message.ToxicityScore.Float64 * float64(message.WordsCount)
+
+		if report.FirstMessageAt.IsZero() || message.CreatedAt.Before(report.FirstMessageAt) {
+			report.FirstMessageAt = message.CreatedAt
+		}
+
+		if message.CreatedAt.After(report.LastMessageAt) {
+			report.LastMessageAt = message.CreatedAt
+		}
+	}
+
+	report.MessagesCount = len(userMessages)
+	if report.MessagesCount == 0 {
+		return report, nil
+	}
+
+	report.TopWords = sortWordCounts(r.countLemmasByUser(filter, userMessages)[input.TgUserID])
+	report.TopWords = report.TopWords[:min(userTopWordsLimit, len(report.TopWords))]
+
+	statsReportChan := make(chan statsReport)
+
+	var (
+		wg       sync.WaitGroup
+		reportWg sync.WaitGroup
+		images   AnaliseReport
+	)
+
+	reportWg.Go(func() {
+		images.appendFromChan(ctx, statsReportChan)
+	})
+
+	wg.Go(func() {
+		r.getUserTimeline(ctx, statsReportChan, userMessages)
+	})
+	wg.Go(func() {
+		r.getUserTopWords(ctx, statsReportChan, report.TopWords)
+	})
+	wg.Go(func() {
+		r.getUserReplyPartners(ctx, statsReportChan, input)
+	})
+
+	wg.Wait()
+	close(statsReportChan)
+	reportWg.Wait()
+
+	report.Images = images.Images
+	slices.SortFunc(report.Images, func(a, b File) int {
+		if a.Name > b.Name {
+			return 1
+		}
+
+		return -1
+	})
+
+	return report, nil
+}
+
+func (r *Service) getUserTimeline(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	messages message_service.Messages,
+) {
+	output := statsReport{
+		repostImage: File{Name: "UserTimeline", Extension: "jpeg"},
+	}
+
+	timeToCount := make(map[string]float64, 100)
+	for _, message := range messages.GroupByTime(time.Hour * 24 * 7) {
+		timeToCount[message.CreatedAt.Format(time.RFC3339)] = float64(message.WordsCount)
+	}
+
+	jpgImg, err := r.drawer.DrawTimeseries(ctx, &ds_supplier.DrawTimeseriesInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:  "Words written by date",
+			XLabel: "Date",
+			YLabel: "Words written",
+		},
+		Values: map[string]map[string]float64{"week": timeToCount},
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw image")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+func (r *Service) getUserTopWords(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	topWords []WordCount,
+) {
+	output := statsReport{
+		repostImage: File{Name: "UserTopWords", Extension: "jpeg"},
+	}
+
+	if len(topWords) == 0 {
+		statsReportChan <- output
+
+		return
+	}
+
+	wordToCount := make(map[string]float64, len(topWords))
+	for _, word := range topWords {
+		wordToCount[word.Word] = float64(word.Count)
+	}
+
+	jpgImg, err := r.drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:  "Favourite words",
+			XLabel: "Word",
+			YLabel: "Times used",
+		},
+		Values: wordToCount,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
+
+// countReplyPartners
+// Counts replies between user and other users, replies in both directions are summed.
+// Replies must be resolved.
+func countReplyPartners(messages message_service.Messages, tgUserID int64) map[int64]uint64 {
+	partners := make(map[int64]uint64, userPartnersLimit)
+
+	for _, reply := range messages.GroupByReplies() {
+		switch tgUserID {
+		case reply.TgUserID:
+			partners[reply.ReplyToTgUserID] += reply.MessagesCount
+		case reply.ReplyToTgUserID:
+			partners[reply.TgUserID] += reply.MessagesCount
+		}
+	}
+
+	return partners
+}
+
+// getUserReplyPartners
+// Draws users, with whom user exchanges replies most.
+func (r *Service) getUserReplyPartners(
+	ctx context.Context,
+	statsReportChan chan<- statsReport,
+	input *AnaliseUserInput,
+) {
+	output := statsReport{
+		repostImage: File{Name: "UserReplyPartners", Extension: "jpeg"},
+	}
+
+	partners := countReplyPartners(input.Storage.Messages, input.TgUserID)
+	if len(partners) == 0 {
+		statsReportChan <- output
+
+		return
+	}
+
+	partnerToCount := make(map[string]float64, len(partners))
+	for partnerID, count := range partners {
+		partnerToCount[input.Storage.UsersNameGetter.GetName(partnerID)] = float64(count)
+	}
+
+	jpgImg, err := r.drawer.DrawBar(ctx, &ds_supplier.DrawBarInput{
+		DrawInput: ds_supplier.DrawInput{
+			Title:  "Favourite reply partners",
+			XLabel: "User",
+			YLabel: "Replies to and from user",
+		},
+		Values: partnerToCount,
+		Limit:  userPartnersLimit,
+	})
+	if err != nil {
+		output.err = errors.Wrap(err, "failed to draw")
+		statsReportChan <- output
+
+		return
+	}
+
+	output.repostImage.Content = jpgImg
+	statsReportChan <- output
+}
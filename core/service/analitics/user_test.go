@@ -0,0 +1,86 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func TestUnit_Analitics_AnaliseUser_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(&drawerMock{image: []byte("image")}, nil)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storage := message_service.Storage{
+		Users: message_service.UsersInChat{
+			{TgID: 1, TgName: "Masha", TgUsername: "masha", Status: message_service.Admin},
+			{TgID: 2, TgName: "Petya"},
+			{TgID: 3, TgName: "Vasya"},
+		},
+		Messages: message_service.Messages{
+			{TgID: 1, TgUserID: 2, CreatedAt: start, Text: "релиз готов"},
+			{
+				TgID:           2,
+				TgUserID:       1,
+				CreatedAt:      start.Add(time.Hour),
+				Text:           "релиз завтра",
+				ReplyToTgMsgID: null.IntFrom(1),
+			},
+			{TgID: 3, TgUserID: 2, CreatedAt: start.Add(time.Hour * 2), Text: "хорошо", ReplyToTgMsgID: null.IntFrom(2)},
+			{TgID: 4, TgUserID: 3, CreatedAt: start.Add(time.Hour * 3), Text: "а я", ReplyToTgMsgID: null.IntFrom(2)},
+			{TgID: 5, TgUserID: 1, CreatedAt: start.Add(time.Hour * 24 * 10), Text: "релиз вышел"},
+			{TgID: 6, TgUserID: 3, CreatedAt: start.Add(time.Hour * 24 * 11), Text: "ура"},
+		},
+	}
+	storage.UsersNameGetter = storage.Users.GetNameGetter()
+
+	report, err := service.AnaliseUser(test_utils.GetLoggedContext(), &AnaliseUserInput{
+		TgChatID: 100,
+		TgUserID: 1,
+		Storage:  storage,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Masha (@masha)", report.Name)
+	assert.Equal(t, message_service.Admin, report.Status)
+	assert.Equal(t, 2, report.MessagesCount)
+	assert.Equal(t, start.Add(time.Hour), report.FirstMessageAt)
+	assert.Equal(t, start.Add(time.Hour*24*10), report.LastMessageAt)
+	require.NotEmpty(t, report.TopWords)
+	assert.Equal(t, WordCount{Word: "релиз", Count: 2}, report.TopWords[0])
+	assert.Len(t, report.Images, 3)
+
+	// Masha replied to Petya once, Petya and Vasya replied to Masha once each
+	assert.Equal(t, map[int64]uint64{2: 2, 3: 1}, countReplyPartners(storage.Messages, 1))
+}
+
+func TestUnit_Analitics_AnaliseUserWithoutMessages_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(&drawerMock{image: []byte("image")}, nil)
+	require.NoError(t, err)
+
+	storage := message_service.Storage{
+		Users:    message_service.UsersInChat{{TgID: 1, TgName: "Masha"}},
+		Messages: message_service.Messages{{TgID: 1, TgUserID: 2, Text: "привет"}},
+	}
+	storage.UsersNameGetter = storage.Users.GetNameGetter()
+
+	report, err := service.AnaliseUser(test_utils.GetLoggedContext(), &AnaliseUserInput{
+		TgChatID: 100,
+		TgUserID: 1,
+		Storage:  storage,
+	})
+	require.NoError(t, err)
+
+	assert.Zero(t, report.MessagesCount)
+	assert.True(t, report.FirstMessageAt.IsZero())
+	assert.Empty(t, report.Images)
+}
@@ -1,6 +1,8 @@
 package analitics
 
 import (
+	"fun_telegram/core/service/message_service"
+	"maps"
 	"strings"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -172,23 +174,72 @@ var serviceWords = mapset.NewSet( //nolint: gochecknoglobals // FIXME
 	"уж",
 )
 
+// lemmaToLemma fixes lemmas, which lemmatizer gets wrong, e.g. "нет" is lemmatized to "житься".
 var lemmaToLemma = map[string]string{"житься": "нет"} //nolint: gochecknoglobals // FIXME
 
-func (r *Service) filterAndLemma(word string) (string, bool) {
-	word = strings.Trim(strings.ToLower(word), "\n.,)(-—/_?!* ")
+// wordsFilter
+// Stop words and lemma overrides, which are applied to words of chat.
+type wordsFilter struct {
+	stopWords    mapset.Set[string]
+	lemmaToLemma map[string]string
+}
+
+// newWordsFilter
+// Applies config on top of default stop words and lemma overrides.
+func newWordsFilter(config *message_service.WordsConfig) *wordsFilter {
+	filter := wordsFilter{
+		stopWords:    serviceWords.Clone(),
+		lemmaToLemma: maps.Clone(lemmaToLemma),
+	}
+
+	for _, stopWord := range config.StopWords {
+		if stopWord.Removed {
+			filter.stopWords.Remove(stopWord.Word)
+		} else {
+			filter.stopWords.Add(stopWord.Word)
+		}
+	}
+
+	for _, override := range config.LemmaOverrides {
+		if override.Lemma == override.Replacement {
+			delete(filter.lemmaToLemma, override.Lemma)
+		} else {
+			filter.lemmaToLemma[override.Lemma] = override.Replacement
+		}
+	}
+
+	return &filter
+}
+
+// NormalizeWord
+// Prepares word to be stored in words config.
+func NormalizeWord(word string) string {
+	return strings.Trim(strings.ToLower(word), "\n.,)(-—/_?!* ")
+}
+
+func (r *Service) filterAndLemma(filter *wordsFilter, word string) (string, bool) {
+	word = NormalizeWord(word)
 	if word == "" || len(word) < 3 {
 		return "", false
 	}
 
 	word = r.lemmatizer.Lemma(word)
-	if serviceWords.Contains(word) {
+
+	// Stop words are checked before overrides, so overridden lemma is counted even if it is a stop word
+	if filter.stopWords.Contains(word) {
 		return "", false
 	}
 
-	convertedWord, ok := lemmaToLemma[word]
+	convertedWord, ok := filter.lemmaToLemma[word]
 	if ok {
 		return convertedWord, true
 	}
 
 	return word, true
 }
+
+// Lemma
+// Returns lemma of word, which is used by word analytics, lemma overrides are not applied.
+func (r *Service) Lemma(word string) string {
+	return r.lemmatizer.Lemma(NormalizeWord(word))
+}
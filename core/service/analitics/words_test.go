@@ -0,0 +1,57 @@
+package analitics
+
+import (
+	"fun_telegram/core/service/message_service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_Analitics_NewWordsFilter_Ok(t *testing.T) {
+	t.Parallel()
+
+	filter := newWordsFilter(&message_service.WordsConfig{
+		StopWords: []message_service.StopWord{
+			{TgChatID: message_service.GlobalTgChatID, Word: "кот"},
+			{TgChatID: 1, Word: "кот", Removed: true},
+			{TgChatID: 1, Word: "собака"},
+			{TgChatID: 1, Word: "где", Removed: true},
+		},
+		LemmaOverrides: []message_service.LemmaOverride{
+			{TgChatID: 1, Lemma: "котик", Replacement: "кот"},
+			{TgChatID: 1, Lemma: "житься", Replacement: "житься"},
+		},
+	})
+
+	assert.False(t, filter.stopWords.Contains("кот"))
+	assert.False(t, filter.stopWords.Contains("где"))
+	assert.True(t, filter.stopWords.Contains("собака"))
+	assert.True(t, filter.stopWords.Contains("как"))
+	assert.Equal(t, map[string]string{"котик": "кот"}, filter.lemmaToLemma)
+
+	// Defaults are not changed by chat config
+	assert.True(t, serviceWords.Contains("где"))
+	assert.Equal(t, "нет", lemmaToLemma["житься"])
+}
+
+func TestUnit_Analitics_FilterAndLemma_Ok(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(nil, nil)
+	require.NoError(t, err)
+
+	filter := newWordsFilter(&message_service.WordsConfig{
+		StopWords: []message_service.StopWord{{TgChatID: 1, Word: "собака"}},
+	})
+
+	word, ok := service.filterAndLemma(filter, "Нет!")
+	assert.True(t, ok)
+	assert.Equal(t, "нет", word)
+
+	_, ok = service.filterAndLemma(filter, "собаки")
+	assert.False(t, ok)
+
+	_, ok = service.filterAndLemma(filter, "как")
+	assert.False(t, ok)
+}
@@ -0,0 +1,124 @@
+package message_service
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// nolint: gochecknoglobals // constant word lists
+var (
+	aliasAdjectives = []string{
+		"Brave", "Calm", "Clever", "Cozy", "Curious", "Daring", "Eager", "Fancy",
+		"Fluffy", "Gentle", "Grumpy", "Happy", "Hasty", "Jolly", "Kind", "Lazy",
+		"Lucky", "Mighty", "Modest", "Noisy", "Polite", "Proud", "Quiet", "Rapid",
+		"Shy", "Silly", "Sleepy", "Sneaky", "Swift", "Tiny", "Witty", "Zealous",
+	}
+	aliasAnimals = []string{
+		"Badger", "Beaver", "Bison", "Camel", "Cat", "Crow", "Deer", "Dolphin",
+		"Eagle", "Ferret", "Fox", "Frog", "Gecko", "Goose", "Hedgehog", "Heron",
+		"Koala", "Lemur", "Lynx", "Marten", "Moose", "Otter", "Owl", "Panda",
+		"Penguin", "Rabbit", "Raccoon", "Seal", "Sloth", "Squirrel", "Walrus", "Wolf",
+	}
+)
+
+// aliasNumbers is amount of numbers appended to aliases, so aliases of users of large chats rarely collide.
+const aliasNumbers = 10000
+
+// userAlias
+// Returns alias, which depends only on salt, chat, user and attempt.
+// Attempt is increased, if alias is taken by other user.
+func userAlias(salt string, tgChatID int64, tgUserID int64, attempt int) string {
+	key := fmt.Sprintf("%s:%d:%d", salt, tgChatID, tgUserID)
+	if attempt != 0 {
+		key += ":" + strconv.Itoa(attempt)
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	value := binary.BigEndian.Uint64(hash[:8])
+
+	adjectives := uint64(len(aliasAdjectives))
+	animals := uint64(len(aliasAnimals))
+
+	return fmt.Sprintf(
+		"%s %s %04d",
+		aliasAdjectives[value%adjectives],
+		aliasAnimals[(value/adjectives)%animals],
+		(value/adjectives/animals)%aliasNumbers,
+	)
+}
+
+type Alias struct {
+	Alias    string
+	TgUserID int64
+}
+
+// GetAnonymizedNameGetter
+// Returns getter, which names users of storage by stable aliases instead of their names.
+func (r *Storage) GetAnonymizedNameGetter(tgChatID int64, salt string) NameGetter {
+	userIDs := make([]int64, 0, len(r.Users))
+	for _, user := range r.Users {
+		userIDs = append(userIDs, user.TgID)
+	}
+
+	for _, message := range r.Messages {
+		userIDs = append(userIDs, message.TgUserID)
+		if message.ReplyToTgUserID.Valid {
+			userIDs = append(userIDs, message.ReplyToTgUserID.Int64)
+		}
+	}
+
+	slices.Sort(userIDs)
+	userIDs = slices.Compact(userIDs)
+
+	getter := r.Users.GetNameGetter()
+	getter.aliases = make(map[int64]string, len(userIDs))
+	taken := make(map[string]struct{}, len(userIDs))
+
+	for _, userID := range userIDs {
+		alias := userAlias(salt, tgChatID, userID, 0)
+
+		// Alias of user is changed only on rare collision, new alias still depends only on user
+		for attempt := 1; ; attempt++ {
+			if _, ok := taken[alias]; !ok {
+				break
+			}
+
+			alias = userAlias(salt, tgChatID, userID, attempt)
+		}
+
+		taken[alias] = struct{}{}
+		getter.aliases[userID] = alias
+	}
+
+	return getter
+}
+
+// GetAliases
+// Returns aliases of anonymized getter sorted by alias.
+func (r *NameGetter) GetAliases() []Alias {
+	aliases := make([]Alias, 0, len(r.aliases))
+	for userID, alias := range r.aliases {
+		aliases = append(aliases, Alias{Alias: alias, TgUserID: userID})
+	}
+
+	slices.SortFunc(aliases, func(a, b Alias) int {
+		if a.Alias < b.Alias {
+			return -1
+		}
+
+		return 1
+	})
+
+	return aliases
+}
+
+// GetRealNameAndUsername
+// Returns name and username of user ignoring aliases.
+func (r *NameGetter) GetRealNameAndUsername(userID int64) string {
+	getter := NameGetter{idToUser: r.idToUser}
+
+	return getter.GetNameAndUsername(userID)
+}
@@ -0,0 +1,64 @@
+package message_service
+
+import (
+	"testing"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_MessageService_GetAnonymizedNameGetter_Ok(t *testing.T) {
+	t.Parallel()
+
+	storage := Storage{
+		Users: UsersInChat{{TgID: 1, TgName: "Masha", TgUsername: "masha"}, {TgID: 2, TgName: "Petya"}},
+		Messages: Messages{
+			{TgUserID: 1},
+			{TgUserID: 3, ReplyToTgUserID: null.IntFrom(4)},
+		},
+	}
+
+	getter := storage.GetAnonymizedNameGetter(100, "salt")
+	again := storage.GetAnonymizedNameGetter(100, "salt")
+	otherChat := storage.GetAnonymizedNameGetter(200, "salt")
+
+	require.True(t, getter.IsAnonymized())
+	assert.Len(t, getter.GetAliases(), 4)
+	assert.Equal(t, getter.GetAliases(), again.GetAliases())
+	assert.NotEqual(t, getter.GetAliases(), otherChat.GetAliases())
+
+	for _, userID := range []int64{1, 2, 3, 4} {
+		assert.NotContains(t, getter.GetName(userID), "Masha")
+		assert.Equal(t, getter.GetName(userID), getter.GetNameAndUsername(userID))
+	}
+
+	assert.Equal(t, "Masha (@masha)", getter.GetRealNameAndUsername(1))
+	assert.Equal(t, "Anonymous", getter.GetName(5))
+}
+
+func TestUnit_MessageService_GetAnonymizedNameGetterStable_Ok(t *testing.T) {
+	t.Parallel()
+
+	small := Storage{Messages: Messages{{TgUserID: 1}, {TgUserID: 2}}}
+
+	large := Storage{}
+	for userID := int64(1); userID <= 5000; userID++ {
+		large.Messages = append(large.Messages, Message{TgUserID: userID * 7})
+	}
+
+	smallGetter := small.GetAnonymizedNameGetter(100, "salt")
+	largeGetter := large.GetAnonymizedNameGetter(100, "salt")
+
+	// Alias does not depend on other users of storage
+	assert.Equal(t, userAlias("salt", 100, 7, 0), largeGetter.GetName(7))
+	assert.Equal(t, userAlias("salt", 100, 1, 0), smallGetter.GetName(1))
+	assert.Equal(t, userAlias("salt", 100, 2, 0), smallGetter.GetName(2))
+
+	unique := make(map[string]struct{}, len(large.Messages))
+	for _, alias := range largeGetter.GetAliases() {
+		unique[alias.Alias] = struct{}{}
+	}
+
+	assert.Len(t, unique, len(large.Messages))
+}
@@ -0,0 +1,77 @@
+package message_service
+
+// ChatSettings
+// Switches of bot in chat, zero value is default behaviour.
+type ChatSettings struct {
+	TgChatID int64 `gorm:"primaryKey;autoIncrement:false"`
+	// Disabled turns off all commands in chat, except of chat config itself.
+	Disabled bool
+	// RepliesDisabled forbids replies into chat, commands of owner are run as silent ones.
+	RepliesDisabled bool
+	// AdminsTrusted gives trusted role to admins and creator of chat, so they can run heavy commands.
+	AdminsTrusted bool
+}
+
+type CommandAccess string
+
+const (
+	CommandAccessDefault CommandAccess = ""
+	// CommandAccessAllow puts command into allow list, if list is not empty, only commands from it are run.
+	CommandAccessAllow CommandAccess = "allow"
+	CommandAccessDeny  CommandAccess = "deny"
+)
+
+// CommandRule
+// Access to command and flags, which are applied to command if not passed explicitly.
+type CommandRule struct {
+	TgChatID     int64  `gorm:"primaryKey;autoIncrement:false"`
+	Command      string `gorm:"primaryKey"`
+	Access       CommandAccess
+	DefaultFlags string
+}
+
+// ChatConfig
+// Settings and command rules of chat.
+type ChatConfig struct {
+	Settings ChatSettings
+	Rules    []CommandRule
+}
+
+func (r *ChatConfig) rule(command string) (CommandRule, bool) {
+	for _, rule := range r.Rules {
+		if rule.Command == command {
+			return rule, true
+		}
+	}
+
+	return CommandRule{}, false
+}
+
+// CommandAllowed
+// Returns true if command can be run in chat.
+func (r *ChatConfig) CommandAllowed(command string) bool {
+	if r.Settings.Disabled {
+		return false
+	}
+
+	rule, ok := r.rule(command)
+	if ok && rule.Access != CommandAccessDefault {
+		return rule.Access == CommandAccessAllow
+	}
+
+	for _, other := range r.Rules {
+		if other.Access == CommandAccessAllow {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DefaultFlags
+// Returns default flags of command, or empty string.
+func (r *ChatConfig) DefaultFlags(command string) string {
+	rule, _ := r.rule(command)
+
+	return rule.DefaultFlags
+}
@@ -0,0 +1,30 @@
+package message_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MessageService_ChatConfigCommandAllowed_Ok(t *testing.T) {
+	t.Parallel()
+
+	config := ChatConfig{}
+	assert.True(t, config.CommandAllowed("stats"))
+
+	config.Rules = []CommandRule{
+		{Command: "stats", Access: CommandAccessDeny},
+		{Command: "search", DefaultFlags: "-p=2"},
+	}
+	assert.False(t, config.CommandAllowed("stats"))
+	assert.True(t, config.CommandAllowed("search"))
+	assert.Equal(t, "-p=2", config.DefaultFlags("search"))
+	assert.Empty(t, config.DefaultFlags("stats"))
+
+	config.Rules = append(config.Rules, CommandRule{Command: "ask", Access: CommandAccessAllow})
+	assert.True(t, config.CommandAllowed("ask"))
+	assert.False(t, config.CommandAllowed("search"))
+
+	config.Settings.Disabled = true
+	assert.False(t, config.CommandAllowed("ask"))
+}
@@ -0,0 +1,228 @@
+package message_service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatJSONL   ExportFormat = "jsonl"
+	ExportFormatParquet ExportFormat = "parquet"
+)
+
+// ParseExportFormat
+// Returns csv if format is empty.
+func ParseExportFormat(format string) (ExportFormat, error) {
+	switch ExportFormat(format) {
+	case "", ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatJSONL:
+		return ExportFormatJSONL, nil
+	case ExportFormatParquet:
+		return ExportFormatParquet, nil
+	default:
+		return "", errors.Errorf("unknown export format: %s", format)
+	}
+}
+
+// ExportMessage
+// Message as it is exported, ids of users are empty if storage is anonymized.
+type ExportMessage struct {
+	TgChatID        int64     `json:"tg_chat_id"                   parquet:"tg_chat_id"`
+	TgID            int       `json:"tg_id"                        parquet:"tg_id"`
+	CreatedAt       time.Time `json:"created_at"                   parquet:"created_at,timestamp(millisecond)"`
+	TgUserID        *int64    `json:"tg_user_id,omitempty"         parquet:"tg_user_id,optional"`
+	User            string    `json:"user"                         parquet:"user"`
+	Text            string    `json:"text"                         parquet:"text"`
+	WordsCount      uint64    `json:"words_count"                  parquet:"words_count"`
+	ToxicityScore   *float64  `json:"toxicity_score,omitempty"     parquet:"toxicity_score,optional"`
+	ReplyToTgMsgID  *int64    `json:"reply_to_tg_msg_id,omitempty" parquet:"reply_to_tg_msg_id,optional"`
+	ReplyToTgUserID *int64    `json:"reply_to_tg_user_id,omitempty" parquet:"reply_to_tg_user_id,optional"`
+	ReplyToUser     string    `json:"reply_to_user,omitempty"      parquet:"reply_to_user,optional"`
+}
+
+// ExportMember
+// Member of chat as it is exported, ids and usernames are empty if storage is anonymized.
+type ExportMember struct {
+	TgID       *int64 `json:"tg_id,omitempty"       parquet:"tg_id,optional"`
+	TgUsername string `json:"tg_username,omitempty" parquet:"tg_username,optional"`
+	User       string `json:"user"                  parquet:"user"`
+	IsBot      bool   `json:"is_bot"                parquet:"is_bot"`
+	Status     string `json:"status"                parquet:"status"`
+}
+
+// ExportMessages
+// Returns messages prepared for export, names are taken from UsersNameGetter.
+// Authors of replied messages are resolved among messages of storage.
+func (r *Storage) ExportMessages() []ExportMessage {
+	r.Messages.ResolveReplies()
+
+	anonymized := r.UsersNameGetter.IsAnonymized()
+	output := make([]ExportMessage, 0, len(r.Messages))
+
+	for _, message := range r.Messages {
+		row := ExportMessage{
+			TgChatID:      message.TgChatID,
+			TgID:          message.TgID,
+			CreatedAt:     message.CreatedAt,
+			User:          r.UsersNameGetter.GetName(message.TgUserID),
+			Text:          message.Text,
+			WordsCount:    message.WordsCount,
+			ToxicityScore: message.ToxicityScore.Ptr(),
+		}
+
+		row.ReplyToTgMsgID = message.ReplyToTgMsgID.Ptr()
+		if message.ReplyToTgUserID.Valid {
+			row.ReplyToUser = r.UsersNameGetter.GetName(message.ReplyToTgUserID.Int64)
+		}
+
+		if !anonymized {
+			row.TgUserID = &message.TgUserID
+			row.ReplyToTgUserID = message.ReplyToTgUserID.Ptr()
+		}
+
+		output = append(output, row)
+	}
+
+	return output
+}
+
+// ExportMembers
+// Returns members prepared for export, names are taken from UsersNameGetter.
+func (r *Storage) ExportMembers() []ExportMember {
+	anonymized := r.UsersNameGetter.IsAnonymized()
+	output := make([]ExportMember, 0, len(r.Users))
+
+	for _, user := range r.Users {
+		row := ExportMember{
+			User:   r.UsersNameGetter.GetName(user.TgID),
+			IsBot:  user.IsBot,
+			Status: string(user.Status),
+		}
+
+		if !anonymized {
+			row.TgID = &user.TgID
+			row.TgUsername = user.TgUsername
+		}
+
+		output = append(output, row)
+	}
+
+	return output
+}
+
+func (r *ExportMessage) csvHeader() []string {
+	return []string{
+		"tg_chat_id", "tg_id", "created_at", "tg_user_id", "user", "text", "words_count",
+		"toxicity_score", "reply_to_tg_msg_id", "reply_to_tg_user_id", "reply_to_user",
+	}
+}
+
+func (r *ExportMessage) csvRecord() []string {
+	return []string{
+		strconv.FormatInt(r.TgChatID, 10),
+		strconv.Itoa(r.TgID),
+		r.CreatedAt.Format(time.RFC3339),
+		formatOptionalInt(r.TgUserID),
+		r.User,
+		r.Text,
+		strconv.FormatUint(r.WordsCount, 10),
+		formatOptionalFloat(r.ToxicityScore),
+		formatOptionalInt(r.ReplyToTgMsgID),
+		formatOptionalInt(r.ReplyToTgUserID),
+		r.ReplyToUser,
+	}
+}
+
+func (r *ExportMember) csvHeader() []string {
+	return []string{"tg_id", "tg_username", "user", "is_bot", "status"}
+}
+
+func (r *ExportMember) csvRecord() []string {
+	return []string{
+		formatOptionalInt(r.TgID),
+		r.TgUsername,
+		r.User,
+		strconv.FormatBool(r.IsBot),
+		r.Status,
+	}
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*v, 10)
+}
+
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+type csvRow interface {
+	csvHeader() []string
+	csvRecord() []string
+}
+
+// Export
+// Writes rows to w in given format.
+func Export[T any, PT interface {
+	*T
+	csvRow
+}](w io.Writer, format ExportFormat, rows []T) error {
+	switch format {
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+
+		err := writer.Write(PT(new(T)).csvHeader())
+		if err != nil {
+			return errors.Wrap(err, "failed to write csv header")
+		}
+
+		for idx := range rows {
+			err = writer.Write(PT(&rows[idx]).csvRecord())
+			if err != nil {
+				return errors.Wrap(err, "failed to write csv record")
+			}
+		}
+
+		writer.Flush()
+
+		err = writer.Error()
+		if err != nil {
+			return errors.Wrap(err, "failed to flush csv")
+		}
+	case ExportFormatJSONL:
+		encoder := json.NewEncoder(w)
+
+		for idx := range rows {
+			err := encoder.Encode(&rows[idx])
+			if err != nil {
+				return errors.Wrap(err, "failed to encode json line")
+			}
+		}
+	case ExportFormatParquet:
+		err := parquet.Write(w, rows)
+		if err != nil {
+			return errors.Wrap(err, "failed to write parquet")
+		}
+	default:
+		return errors.Errorf("unknown export format: %s", format)
+	}
+
+	return nil
+}
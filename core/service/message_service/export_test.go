@@ -0,0 +1,81 @@
+package message_service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getExportStorage() Storage {
+	storage := Storage{
+		Messages: Messages{
+			{TgChatID: 1, TgID: 1, TgUserID: 10, Text: "привет, мир", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{
+				TgChatID:       1,
+				TgID:           2,
+				TgUserID:       20,
+				Text:           "ответ",
+				CreatedAt:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+				ToxicityScore:  null.FloatFrom(0.5),
+				ReplyToTgMsgID: null.IntFrom(1),
+			},
+		},
+		Users: UsersInChat{
+			{TgChatID: 1, TgID: 10, TgName: "Peter", TgUsername: "peter", Status: Admin},
+			{TgChatID: 1, TgID: 20, TgName: "Ann", TgUsername: "ann", Status: Plain},
+		},
+	}
+	storage.UsersNameGetter = storage.Users.GetNameGetter()
+
+	return storage
+}
+
+func TestUnit_MessageService_ExportCSV_Ok(t *testing.T) {
+	t.Parallel()
+
+	storage := getExportStorage()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, Export(&buf, ExportFormatCSV, storage.ExportMessages()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, `1,1,2024-01-01T00:00:00Z,10,Peter,"привет, мир",0,,,,`, lines[1])
+	assert.Equal(t, `1,2,2024-01-02T00:00:00Z,20,Ann,ответ,0,0.5,1,10,Peter`, lines[2])
+}
+
+func TestUnit_MessageService_ExportAnonymizedJSONL_Ok(t *testing.T) {
+	t.Parallel()
+
+	storage := getExportStorage()
+	storage.UsersNameGetter = storage.GetAnonymizedNameGetter(1, "salt")
+
+	var buf bytes.Buffer
+
+	require.NoError(t, Export(&buf, ExportFormatJSONL, storage.ExportMembers()))
+
+	assert.NotContains(t, buf.String(), "peter")
+	assert.NotContains(t, buf.String(), "tg_id")
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 2)
+}
+
+func TestUnit_MessageService_ExportParquet_Ok(t *testing.T) {
+	t.Parallel()
+
+	storage := getExportStorage()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, Export(&buf, ExportFormatParquet, storage.ExportMessages()))
+
+	rows, err := parquet.Read[ExportMessage](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, storage.ExportMessages(), rows)
+}
@@ -4,14 +4,17 @@ import (
 	"maps"
 	"slices"
 	"time"
+
+	"github.com/guregu/null/v5"
 )
 
 type MessageGroupByUserID struct {
 	TgUserID int64
 
-	WordsCount      uint64
-	ToxicWordsCount uint64
-	MessagesCount   uint64
+	WordsCount uint64
+	// ToxicWords is amount of words weighted by toxicity score of their messages.
+	ToxicWords    float64
+	MessagesCount uint64
 }
 
 type MessagesGroupByUserID []MessageGroupByUserID
@@ -44,7 +47,7 @@ func (r *Messages) GroupByUserID() MessagesGroupByUserID {
 			user.TgUserID = m.TgUserID
 		}
 
-		user.ToxicWordsCount += m.ToxicWordsCount
+		user.ToxicWords += m.ToxicityScore.Float64 * float64(m.WordsCount)
 		user.WordsCount += m.WordsCount
 		user.MessagesCount++
 
@@ -78,3 +81,238 @@ func (r *Messages) GroupByTime(precision time.Duration) MessagesGroupByTime {
 
 	return slices.Collect(maps.Values(msgs))
 }
+
+// ResolveReplies
+// Fills ReplyToTgUserID from ReplyToTgMsgID, replied messages are searched among given ones.
+func (r Messages) ResolveReplies() {
+	tgIDToUserID := make(map[int]int64, len(r))
+	for _, m := range r {
+		tgIDToUserID[m.TgID] = m.TgUserID
+	}
+
+	for idx, m := range r {
+		if !m.ReplyToTgMsgID.Valid {
+			continue
+		}
+
+		userID, ok := tgIDToUserID[int(m.ReplyToTgMsgID.Int64)]
+		if !ok {
+			continue
+		}
+
+		r[idx].ReplyToTgUserID = null.IntFrom(userID)
+	}
+}
+
+// ReplyThread
+// Returns messages connected to message with tgID by replies: its ancestors and all replies to them.
+// Returns nil, if message with tgID is not among given ones.
+func (r Messages) ReplyThread(tgID int) Messages {
+	byTgID := make(map[int]Message, len(r))
+	children := make(map[int][]int, len(r))
+
+	for _, m := range r {
+		byTgID[m.TgID] = m
+
+		if m.ReplyToTgMsgID.Valid {
+			parent := int(m.ReplyToTgMsgID.Int64)
+			children[parent] = append(children[parent], m.TgID)
+		}
+	}
+
+	root, ok := byTgID[tgID]
+	if !ok {
+		return nil
+	}
+
+	visited := map[int]struct{}{root.TgID: {}}
+
+	for root.ReplyToTgMsgID.Valid {
+		parent, ok := byTgID[int(root.ReplyToTgMsgID.Int64)]
+		if !ok {
+			break
+		}
+
+		if _, ok = visited[parent.TgID]; ok {
+			break
+		}
+
+		visited[parent.TgID] = struct{}{}
+		root = parent
+	}
+
+	thread := make(Messages, 0, len(visited))
+	queue := []int{root.TgID}
+	clear(visited)
+	visited[root.TgID] = struct{}{}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		thread = append(thread, byTgID[current])
+
+		for _, child := range children[current] {
+			if _, ok = visited[child]; ok {
+				continue
+			}
+
+			visited[child] = struct{}{}
+			queue = append(queue, child)
+		}
+	}
+
+	return thread
+}
+
+// AfterLastOfUser
+// Returns messages newer than the last message of user, false is returned if user has no messages.
+func (r Messages) AfterLastOfUser(tgUserID int64) (Messages, bool) {
+	lastTgID := 0
+
+	for _, m := range r {
+		if m.TgUserID == tgUserID && m.TgID > lastTgID {
+			lastTgID = m.TgID
+		}
+	}
+
+	if lastTgID == 0 {
+		return nil, false
+	}
+
+	output := make(Messages, 0, len(r))
+
+	for _, m := range r {
+		if m.TgID > lastTgID {
+			output = append(output, m)
+		}
+	}
+
+	return output, true
+}
+
+type MessageGroupByReply struct {
+	TgUserID        int64
+	ReplyToTgUserID int64
+
+	MessagesCount uint64
+}
+
+type MessagesGroupByReply []MessageGroupByReply
+
+// GroupByReplies
+// Counts replies from one user to another, replies to own messages are skipped.
+func (r *Messages) GroupByReplies() MessagesGroupByReply {
+	type edge struct {
+		from int64
+		to   int64
+	}
+
+	edges := make(map[edge]MessageGroupByReply)
+
+	for _, m := range *r {
+		if !m.ReplyToTgUserID.Valid || m.ReplyToTgUserID.Int64 == m.TgUserID {
+			continue
+		}
+
+		key := edge{from: m.TgUserID, to: m.ReplyToTgUserID.Int64}
+
+		group, ok := edges[key]
+		if !ok {
+			group.TgUserID = key.from
+			group.ReplyToTgUserID = key.to
+		}
+
+		group.MessagesCount++
+		edges[key] = group
+	}
+
+	return slices.Collect(maps.Values(edges))
+}
+
+func (r *MessagesGroupByReply) SortByMessagesCount() {
+	slices.SortFunc(*r, func(a, b MessageGroupByReply) int {
+		if a.MessagesCount > b.MessagesCount {
+			return -1
+		}
+
+		return 1
+	})
+}
+
+// MessagesGroupByWeekdayHour
+// Messages count by weekday, starting from Monday, and hour of day.
+type MessagesGroupByWeekdayHour [7][24]uint64
+
+// weekdayIdx
+// Returns index of weekday, where Monday is 0 and Sunday is 6.
+func weekdayIdx(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// GroupByWeekdayHour
+// Counts messages by weekday and hour, time of messages is converted to location.
+func (r *Messages) GroupByWeekdayHour(location *time.Location) MessagesGroupByWeekdayHour {
+	var counts MessagesGroupByWeekdayHour
+
+	for _, m := range *r {
+		createdAt := m.CreatedAt.In(location)
+		counts[weekdayIdx(createdAt.Weekday())][createdAt.Hour()]++
+	}
+
+	return counts
+}
+
+type MessageGroupByPeakHour struct {
+	TgUserID int64
+
+	PeakHour          int
+	PeakMessagesCount uint64
+	MessagesCount     uint64
+}
+
+type MessagesGroupByPeakHour []MessageGroupByPeakHour
+
+// GroupByPeakHour
+// Finds hour of day, in which each user writes most of messages, earliest hour wins on tie.
+func (r *Messages) GroupByPeakHour(location *time.Location) MessagesGroupByPeakHour {
+	hours := make(map[int64]*[24]uint64)
+
+	for _, m := range *r {
+		userHours, ok := hours[m.TgUserID]
+		if !ok {
+			userHours = &[24]uint64{}
+			hours[m.TgUserID] = userHours
+		}
+
+		userHours[m.CreatedAt.In(location).Hour()]++
+	}
+
+	users := make(MessagesGroupByPeakHour, 0, len(hours))
+
+	for userID, userHours := range hours {
+		user := MessageGroupByPeakHour{TgUserID: userID}
+
+		for hour, count := range userHours {
+			user.MessagesCount += count
+			if count > user.PeakMessagesCount {
+				user.PeakHour = hour
+				user.PeakMessagesCount = count
+			}
+		}
+
+		users = append(users, user)
+	}
+
+	return users
+}
+
+func (r *MessagesGroupByPeakHour) SortByMessagesCount() {
+	slices.SortFunc(*r, func(a, b MessageGroupByPeakHour) int {
+		if a.MessagesCount > b.MessagesCount {
+			return -1
+		}
+
+		return 1
+	})
+}
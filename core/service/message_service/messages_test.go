@@ -0,0 +1,116 @@
+package message_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_MessageService_GroupByReplies_Ok(t *testing.T) {
+	t.Parallel()
+
+	messages := Messages{
+		{TgID: 1, TgUserID: 10},
+		{TgID: 2, TgUserID: 20, ReplyToTgMsgID: null.IntFrom(1)},
+		{TgID: 3, TgUserID: 20, ReplyToTgMsgID: null.IntFrom(1)},
+		{TgID: 4, TgUserID: 10, ReplyToTgMsgID: null.IntFrom(3)},
+		{TgID: 5, TgUserID: 10, ReplyToTgMsgID: null.IntFrom(4)},
+		{TgID: 6, TgUserID: 30, ReplyToTgMsgID: null.IntFrom(100)},
+	}
+
+	messages.ResolveReplies()
+
+	assert.Equal(t, null.IntFrom(10), messages[1].ReplyToTgUserID)
+	assert.False(t, messages[5].ReplyToTgUserID.Valid)
+
+	replies := messages.GroupByReplies()
+	replies.SortByMessagesCount()
+
+	require.Len(t, replies, 2)
+	assert.Equal(t, MessageGroupByReply{TgUserID: 20, ReplyToTgUserID: 10, MessagesCount: 2}, replies[0])
+	assert.Equal(t, MessageGroupByReply{TgUserID: 10, ReplyToTgUserID: 20, MessagesCount: 1}, replies[1])
+}
+
+func TestUnit_MessageService_GroupByWeekdayHour_Ok(t *testing.T) {
+	t.Parallel()
+
+	location := time.FixedZone("UTC+3", 3*60*60)
+	messages := Messages{
+		// Monday 22:30 UTC is Tuesday 01:30 in UTC+3.
+		{TgUserID: 10, CreatedAt: time.Date(2024, 1, 1, 22, 30, 0, 0, time.UTC)},
+		{TgUserID: 10, CreatedAt: time.Date(2024, 1, 2, 22, 10, 0, 0, time.UTC)},
+		{TgUserID: 10, CreatedAt: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)},
+		{TgUserID: 20, CreatedAt: time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC)},
+	}
+
+	counts := messages.GroupByWeekdayHour(location)
+
+	assert.Equal(t, uint64(1), counts[1][1])
+	assert.Equal(t, uint64(1), counts[2][1])
+	assert.Equal(t, uint64(1), counts[2][12])
+	assert.Equal(t, uint64(1), counts[6][12])
+	assert.Equal(t, uint64(0), counts[0][22])
+
+	users := messages.GroupByPeakHour(location)
+	users.SortByMessagesCount()
+
+	require.Len(t, users, 2)
+	assert.Equal(t, MessageGroupByPeakHour{
+		TgUserID:          10,
+		PeakHour:          1,
+		PeakMessagesCount: 2,
+		MessagesCount:     3,
+	}, users[0])
+	assert.Equal(t, MessageGroupByPeakHour{
+		TgUserID:          20,
+		PeakHour:          12,
+		PeakMessagesCount: 1,
+		MessagesCount:     1,
+	}, users[1])
+}
+
+func TestUnit_MessageService_ReplyThread_Ok(t *testing.T) {
+	t.Parallel()
+
+	messages := Messages{
+		{TgID: 1, TgUserID: 10},
+		{TgID: 2, TgUserID: 20, ReplyToTgMsgID: null.IntFrom(1)},
+		{TgID: 3, TgUserID: 30},
+		{TgID: 4, TgUserID: 10, ReplyToTgMsgID: null.IntFrom(2)},
+		{TgID: 5, TgUserID: 30, ReplyToTgMsgID: null.IntFrom(1)},
+		{TgID: 6, TgUserID: 20, ReplyToTgMsgID: null.IntFrom(3)},
+	}
+
+	thread := messages.ReplyThread(4)
+
+	tgIDs := make([]int, 0, len(thread))
+	for _, m := range thread {
+		tgIDs = append(tgIDs, m.TgID)
+	}
+
+	assert.ElementsMatch(t, []int{1, 2, 4, 5}, tgIDs)
+	assert.Nil(t, messages.ReplyThread(100))
+}
+
+func TestUnit_MessageService_AfterLastOfUser_Ok(t *testing.T) {
+	t.Parallel()
+
+	messages := Messages{
+		{TgID: 4, TgUserID: 20},
+		{TgID: 3, TgUserID: 10},
+		{TgID: 2, TgUserID: 20},
+		{TgID: 1, TgUserID: 10},
+	}
+
+	after, found := messages.AfterLastOfUser(10)
+
+	require.True(t, found)
+	require.Len(t, after, 1)
+	assert.Equal(t, 4, after[0].TgID)
+
+	_, found = messages.AfterLastOfUser(30)
+	assert.False(t, found)
+}
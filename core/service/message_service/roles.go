@@ -0,0 +1,56 @@
+package message_service
+
+import "time"
+
+// Role
+// Level of access to commands, each role includes rights of lower ones.
+type Role int
+
+const (
+	RoleAnyone Role = iota
+	// RoleAdmin is role of admins and creator of chat.
+	RoleAdmin
+	// RoleTrusted is role of users, who are granted access by owner, and of admins of chats, which trust them.
+	RoleTrusted
+	// RoleOwner is role of account, which bot runs on.
+	RoleOwner
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleAnyone:
+		return "anyone"
+	case RoleAdmin:
+		return "admin"
+	case RoleTrusted:
+		return "trusted"
+	case RoleOwner:
+		return "owner"
+	default:
+		return "unknown"
+	}
+}
+
+// Grant
+// Makes user trusted in chat, or in all chats if TgChatID is GlobalTgChatID.
+type Grant struct {
+	TgChatID int64 `gorm:"primaryKey;autoIncrement:false"`
+	TgUserID int64 `gorm:"primaryKey;autoIncrement:false"`
+
+	CreatedAt time.Time
+}
+
+// RoleOf
+// Returns role of user in chat, owner role is not detected here.
+// Admins are trusted only if chat settings allow it, so heavy commands are not open to admins by default.
+func RoleOf(admin bool, granted bool, settings *ChatSettings) Role {
+	if granted || (admin && settings.AdminsTrusted) {
+		return RoleTrusted
+	}
+
+	if admin {
+		return RoleAdmin
+	}
+
+	return RoleAnyone
+}
@@ -0,0 +1,44 @@
+package message_service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MessageService_RoleOrder_Ok(t *testing.T) {
+	t.Parallel()
+
+	roles := []Role{RoleAnyone, RoleAdmin, RoleTrusted, RoleOwner}
+	for idx := 1; idx < len(roles); idx++ {
+		assert.Less(t, roles[idx-1], roles[idx], "%s must be lower than %s", roles[idx-1], roles[idx])
+	}
+}
+
+func TestUnit_MessageService_RoleOf_Ok(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name          string
+		admin         bool
+		granted       bool
+		adminsTrusted bool
+		expected      Role
+	}{
+		{name: "anyone", expected: RoleAnyone},
+		{name: "anyone in chat with trusted admins", adminsTrusted: true, expected: RoleAnyone},
+		{name: "admin", admin: true, expected: RoleAdmin},
+		{name: "trusted admin", admin: true, adminsTrusted: true, expected: RoleTrusted},
+		{name: "granted", granted: true, expected: RoleTrusted},
+		{name: "granted admin", admin: true, granted: true, expected: RoleTrusted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			role := RoleOf(tc.admin, tc.granted, &ChatSettings{AdminsTrusted: tc.adminsTrusted})
+			assert.Equal(t, tc.expected, role)
+		})
+	}
+}
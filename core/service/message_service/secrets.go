@@ -0,0 +1,11 @@
+package message_service
+
+// SecretAnonymizeSalt is name of salt of aliases of anonymized users, if it is not set in settings.
+const SecretAnonymizeSalt = "anonymize_salt"
+
+// Secret
+// Random value, which is generated on first use and kept between restarts.
+type Secret struct {
+	Name  string `gorm:"primaryKey"`
+	Value string
+}
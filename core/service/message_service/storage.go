@@ -7,16 +7,23 @@ import (
 )
 
 type Message struct {
-	ID        uint
-	CreatedAt time.Time
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"index"`
 
-	TgChatID int64
-	TgID     int
+	TgChatID int64 `gorm:"uniqueIndex:idx_message_chat_tg_id"`
+	TgID     int   `gorm:"uniqueIndex:idx_message_chat_tg_id"`
 
-	TgUserID        int64
-	Text            string
-	WordsCount      uint64
-	ToxicWordsCount uint64
+	TgUserID   int64
+	Text       string
+	WordsCount uint64
+	// ToxicityScore is from 0 to 1, null if message is not scored yet.
+	ToxicityScore null.Float
+	// ToxicityScorer is name of scorer, which ToxicityScore is computed by.
+	ToxicityScorer string
+	// Lemmas are lemmas of words of text separated by space, they are indexed for search.
+	// Stop words and lemma overrides are not applied, so search applies ones of chat.
+	// Lemmas are null if message is not indexed yet.
+	Lemmas null.String
 
 	ReplyToTgMsgID  null.Int64
 	ReplyToTgUserID null.Int64
@@ -25,7 +32,8 @@ type Message struct {
 type Messages []Message
 
 type UserInChat struct {
-	TgID       int64
+	TgChatID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	TgID       int64 `gorm:"primaryKey;autoIncrement:false"`
 	TgUsername string
 	TgName     string
 	IsBot      bool
@@ -55,4 +63,6 @@ type Storage struct {
 
 	Users           UsersInChat
 	UsersNameGetter NameGetter
+
+	WordsConfig WordsConfig
 }
@@ -0,0 +1,102 @@
+package message_service
+
+import "time"
+
+// SyncState
+// Describes which part of chat history is already stored.
+// Messages between OldestTgID and NewestTgID are stored without holes.
+type SyncState struct {
+	TgChatID int64 `gorm:"primaryKey;autoIncrement:false"`
+
+	NewestTgID      int
+	OldestTgID      int
+	OldestCreatedAt time.Time
+	// HistoryStartReached is set when the first message of chat is stored.
+	HistoryStartReached bool
+
+	// GapTopTgID and GapOffsetTgID are bounds of an unfinished upload above NewestTgID
+	// or of messages captured after bot was offline.
+	// Upload is continued from GapOffsetTgID down to NewestTgID.
+	GapTopTgID    int
+	GapOffsetTgID int
+
+	UpdatedAt time.Time
+}
+
+func (r *SyncState) IsEmpty() bool {
+	return r.NewestTgID == 0
+}
+
+func (r *SyncState) HasGap() bool {
+	return r.GapTopTgID != 0
+}
+
+// CloseGap
+// Marks unfinished upload above NewestTgID as done.
+func (r *SyncState) CloseGap() {
+	if !r.HasGap() {
+		return
+	}
+
+	r.NewestTgID = r.GapTopTgID
+	r.GapTopTgID = 0
+	r.GapOffsetTgID = 0
+}
+
+// ExtendByImported
+// Extends synced range by imported messages, which overlap with it.
+// Empty state is left untouched, as it is initialized from stored messages.
+func (r *SyncState) ExtendByImported(oldest, newest *Message) {
+	if r.IsEmpty() {
+		return
+	}
+
+	if oldest.TgID < r.OldestTgID && newest.TgID >= r.OldestTgID {
+		r.OldestTgID = oldest.TgID
+		r.OldestCreatedAt = oldest.CreatedAt
+	}
+
+	if !r.HasGap() && newest.TgID > r.NewestTgID && oldest.TgID <= r.NewestTgID {
+		r.NewestTgID = newest.TgID
+	}
+}
+
+// ExtendByCaptured
+// Extends synced range by captured message, prevCapturedTgID is the previous message of chat captured since boot.
+// Messages between previous and current captured ones are stored, so range is moved if it covers previous one.
+// Otherwise messages sent while bot was offline are recorded as gap, so next upload fills it.
+// Returns false if state is not changed.
+func (r *SyncState) ExtendByCaptured(tgID int, prevCapturedTgID int) bool {
+	if r.IsEmpty() {
+		return false
+	}
+
+	top := r.NewestTgID
+	if r.HasGap() {
+		top = r.GapTopTgID
+	}
+
+	if tgID <= top {
+		return false
+	}
+
+	if prevCapturedTgID != 0 && prevCapturedTgID <= top {
+		if r.HasGap() {
+			r.GapTopTgID = tgID
+		} else {
+			r.NewestTgID = tgID
+		}
+
+		return true
+	}
+
+	// Only one gap is tracked, messages above unfinished upload are uploaded after it is finished
+	if r.HasGap() {
+		return false
+	}
+
+	r.GapTopTgID = tgID
+	r.GapOffsetTgID = tgID
+
+	return true
+}
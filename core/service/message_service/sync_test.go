@@ -0,0 +1,68 @@
+package message_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MessageService_ExtendByImported_Ok(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	state := SyncState{NewestTgID: 100, OldestTgID: 50, OldestCreatedAt: now}
+
+	// Import older than stored range with a hole is not merged
+	state.ExtendByImported(&Message{TgID: 1}, &Message{TgID: 40})
+	assert.Equal(t, 50, state.OldestTgID)
+
+	state.ExtendByImported(&Message{TgID: 10, CreatedAt: now.Add(-time.Hour)}, &Message{TgID: 120})
+	assert.Equal(t, 10, state.OldestTgID)
+	assert.Equal(t, now.Add(-time.Hour), state.OldestCreatedAt)
+	assert.Equal(t, 120, state.NewestTgID)
+
+	empty := SyncState{}
+	empty.ExtendByImported(&Message{TgID: 10}, &Message{TgID: 120})
+	assert.True(t, empty.IsEmpty())
+}
+
+func TestUnit_MessageService_ExtendByCaptured_Ok(t *testing.T) {
+	t.Parallel()
+
+	state := SyncState{NewestTgID: 100, OldestTgID: 50}
+
+	// First captured message after boot may be preceded by messages sent while bot was offline
+	assert.True(t, state.ExtendByCaptured(110, 0))
+	assert.Equal(t, 100, state.NewestTgID)
+	assert.Equal(t, 110, state.GapTopTgID)
+	assert.Equal(t, 110, state.GapOffsetTgID)
+
+	assert.True(t, state.ExtendByCaptured(111, 110))
+	assert.Equal(t, 111, state.GapTopTgID)
+	assert.Equal(t, 110, state.GapOffsetTgID)
+
+	assert.False(t, state.ExtendByCaptured(111, 111))
+
+	state.CloseGap()
+	assert.Equal(t, 111, state.NewestTgID)
+
+	assert.True(t, state.ExtendByCaptured(112, 111))
+	assert.Equal(t, 112, state.NewestTgID)
+	assert.False(t, state.HasGap())
+
+	// Previous captured message is not covered, if state was overwritten by upload
+	state.NewestTgID = 105
+	assert.True(t, state.ExtendByCaptured(113, 112))
+	assert.Equal(t, 105, state.NewestTgID)
+	assert.Equal(t, 113, state.GapTopTgID)
+
+	// Second gap is not tracked
+	state.GapTopTgID, state.GapOffsetTgID = 120, 115
+	assert.False(t, state.ExtendByCaptured(130, 0))
+	assert.Equal(t, 120, state.GapTopTgID)
+
+	empty := SyncState{}
+	assert.False(t, empty.ExtendByCaptured(10, 0))
+	assert.True(t, empty.IsEmpty())
+}
@@ -7,9 +7,19 @@ import (
 
 type NameGetter struct {
 	idToUser map[int64]UserInChat
+	// aliases replace names of users, if getter is anonymized.
+	aliases map[int64]string
+}
+
+func (r *NameGetter) IsAnonymized() bool {
+	return r.aliases != nil
 }
 
 func (r *NameGetter) GetName(userID int64) string {
+	if r.IsAnonymized() {
+		return r.getAlias(userID)
+	}
+
 	user, ok := r.idToUser[userID]
 	if !ok || strings.TrimSpace(user.TgName) == "" {
 		return fmt.Sprintf("id: %d", userID)
@@ -19,6 +29,10 @@ func (r *NameGetter) GetName(userID int64) string {
 }
 
 func (r *NameGetter) GetNameAndUsername(userID int64) string {
+	if r.IsAnonymized() {
+		return r.getAlias(userID)
+	}
+
 	user, ok := r.idToUser[userID]
 	if !ok || (strings.TrimSpace(user.TgName) == "" && strings.TrimSpace(user.TgUsername) == "") {
 		return fmt.Sprintf("id: %d", userID)
@@ -38,3 +52,12 @@ func (r UsersInChat) GetNameGetter() NameGetter {
 
 	return getter
 }
+
+func (r *NameGetter) getAlias(userID int64) string {
+	alias, ok := r.aliases[userID]
+	if !ok {
+		return "Anonymous"
+	}
+
+	return alias
+}
@@ -0,0 +1,29 @@
+package message_service
+
+// GlobalTgChatID is used as chat id of settings, which are applied to all chats.
+const GlobalTgChatID int64 = 0
+
+// StopWord
+// Word, which is skipped by word analytics. Removed stop word cancels the same stop word from
+// global settings or defaults.
+type StopWord struct {
+	TgChatID int64  `gorm:"primaryKey;autoIncrement:false"`
+	Word     string `gorm:"primaryKey"`
+	Removed  bool
+}
+
+// LemmaOverride
+// Replaces lemma with another one, override to the lemma itself cancels the override from
+// global settings or defaults.
+type LemmaOverride struct {
+	TgChatID    int64  `gorm:"primaryKey;autoIncrement:false"`
+	Lemma       string `gorm:"primaryKey"`
+	Replacement string
+}
+
+// WordsConfig
+// Stop words and lemma overrides of chat, global ones go first.
+type WordsConfig struct {
+	StopWords      []StopWord
+	LemmaOverrides []LemmaOverride
+}
@@ -0,0 +1,83 @@
+package summarize_service
+
+import (
+	"context"
+	"fmt"
+	"fun_telegram/core/supplier/llm_supplier"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const askPrompt = "Ты - умный бот, который отвечает на вопросы по переписке в телеграмме. " +
+	"Ниже тебе будут отправлены сообщения чата, каждое начинается с номера в квадратных скобках. " +
+	"Ответь на вопрос, используя только эти сообщения. " +
+	"После каждого утверждения укажи номера сообщений, на которых оно основано, например [2]. " +
+	"Если в сообщениях нет ответа, так и скажи."
+
+type AskInput struct {
+	Question string
+	// Sources are messages ordered by relevance, each of them is prefixed by its number, e.g. [1].
+	// Least relevant sources are dropped, if they do not fit into token budget.
+	Sources []string
+	// OnPartial is called with generated part of answer as it is streamed, can be nil.
+	OnPartial func(answer string)
+}
+
+// Ask
+// Answers question about chat using given sources, answer cites numbers of sources.
+func (r *Service) Ask(ctx context.Context, input *AskInput) (string, error) {
+	if len(input.Sources) == 0 {
+		return "", errors.New("no sources to answer from")
+	}
+
+	budget := r.tokenBudget - EstimateTokens(askPrompt) - EstimateTokens(input.Question)
+	if budget < minTokenBudget/2 {
+		return "", errors.New("question does not fit into token budget")
+	}
+
+	sources := chunkLines(input.Sources, budget)[0]
+
+	answer, err := r.completeFinal(ctx, input.OnPartial, []llm_supplier.Message{
+		{Role: llm_supplier.RoleSystem, Content: askPrompt},
+		{Role: llm_supplier.RoleUser, Content: fmt.Sprintf(
+			"Сообщения:\n%s\n\nВопрос: %s",
+			strings.Join(sources, "\n"),
+			input.Question,
+		)},
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to answer")
+	}
+
+	return answer, nil
+}
+
+var citationRegexp = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)]`) //nolint: gochecknoglobals // FIXME
+
+// CitedSources
+// Returns numbers of sources cited in answer in order of first citation, numbers out of range are skipped.
+func CitedSources(answer string, sourcesCount int) []int {
+	cited := make([]int, 0, sourcesCount)
+	seen := make(map[int]struct{}, sourcesCount)
+
+	for _, match := range citationRegexp.FindAllStringSubmatch(answer, -1) {
+		for numberS := range strings.SplitSeq(match[1], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(numberS))
+			if err != nil || number < 1 || number > sourcesCount {
+				continue
+			}
+
+			if _, ok := seen[number]; ok {
+				continue
+			}
+
+			seen[number] = struct{}{}
+			cited = append(cited, number)
+		}
+	}
+
+	return cited
+}
@@ -0,0 +1,213 @@
+package summarize_service
+
+import (
+	"context"
+	"fun_telegram/core/supplier/llm_supplier"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+type Service struct {
+	llm         llm_supplier.LLM
+	tokenBudget int
+}
+
+func New(llm llm_supplier.LLM, tokenBudget int) (*Service, error) {
+	if tokenBudget < minTokenBudget {
+		return nil, errors.Errorf("token budget must be at least %d", minTokenBudget)
+	}
+
+	return &Service{llm: llm, tokenBudget: tokenBudget}, nil
+}
+
+const (
+	// charsPerToken is rough estimate for cyrillic texts, real tokenizer of llm is not available
+	charsPerToken  = 3
+	minTokenBudget = 500
+
+	mapPrompt = "Ниже только часть переписки, следующие части будут сумаризированы отдельно. " +
+		"Сумаризируй эту часть кратко, сохрани имена участников и основные темы."
+	reducePrompt = "Ниже краткие изложения последовательных частей одной переписки в хронологическом порядке. " +
+		"Объедини их в одно изложение."
+)
+
+// EstimateTokens
+// Returns approximate amount of tokens in text.
+func EstimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/charsPerToken + 1
+}
+
+// chunkLines
+// Splits lines into chunks, each of them fits into budget of tokens.
+// Line, which does not fit into budget by itself, is truncated.
+func chunkLines(lines []string, budget int) [][]string {
+	chunks := make([][]string, 0, 1)
+	chunk := make([]string, 0, len(lines))
+	chunkTokens := 0
+
+	for _, line := range lines {
+		tokens := EstimateTokens(line)
+		if tokens > budget {
+			line = string([]rune(line)[:(budget-1)*charsPerToken])
+			tokens = EstimateTokens(line)
+		}
+
+		if chunkTokens+tokens > budget && len(chunk) != 0 {
+			chunks = append(chunks, chunk)
+			chunk = make([]string, 0, len(lines))
+			chunkTokens = 0
+		}
+
+		chunk = append(chunk, line)
+		chunkTokens += tokens
+	}
+
+	if len(chunk) != 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+type Stage string
+
+const (
+	StageMap    Stage = "map"
+	StageReduce Stage = "reduce"
+)
+
+type Progress struct {
+	Stage Stage
+	// Level is number of reduce pass, 0 for map stage.
+	Level int
+	Done  int
+	Total int
+}
+
+type SummarizeInput struct {
+	// Prompt describes what is summarized, it is used as system message of every request.
+	Prompt string
+	// Lines are summarized in given order, usually one line is one message.
+	Lines []string
+	// OnProgress is called after each request to llm, can be nil.
+	OnProgress func(progress Progress)
+	// OnPartial is called with generated part of final summary as it is streamed, can be nil.
+	OnPartial func(summary string)
+}
+
+// Summarize
+// Summarizes lines in one request if they fit into token budget.
+// Otherwise lines are split into chunks, which are summarized separately,
+// then summaries are summarized the same way until one summary is left.
+func (r *Service) Summarize(ctx context.Context, input *SummarizeInput) (string, error) {
+	if len(input.Lines) == 0 {
+		return "", errors.New("nothing to summarize")
+	}
+
+	budget := r.tokenBudget - EstimateTokens(input.Prompt) - EstimateTokens(reducePrompt) - EstimateTokens(mapPrompt)
+	if budget < minTokenBudget/2 {
+		return "", errors.New("prompt does not fit into token budget")
+	}
+
+	lines := input.Lines
+	stage := StageMap
+
+	for level := 0; ; level++ {
+		chunks := chunkLines(lines, budget)
+		if len(chunks) == 1 {
+			prompt := input.Prompt
+			if stage == StageReduce {
+				prompt += "\n" + reducePrompt
+			}
+
+			summary, err := r.completeFinal(ctx, input.OnPartial, newMessages(prompt, chunks[0]))
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+
+			notifyProgress(input, Progress{Stage: stage, Level: level, Done: 1, Total: 1})
+
+			return summary, nil
+		}
+
+		if stage == StageReduce && len(chunks) >= len(lines) {
+			return "", errors.New("summaries do not become shorter, increase token budget")
+		}
+
+		prompt := input.Prompt + "\n" + mapPrompt
+		if stage == StageReduce {
+			prompt = input.Prompt + "\n" + reducePrompt + "\n" + mapPrompt
+		}
+
+		summaries := make([]string, 0, len(chunks))
+
+		for idx, chunk := range chunks {
+			summary, err := r.complete(ctx, prompt, chunk)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to summarize chunk %d of %d", idx+1, len(chunks))
+			}
+
+			summaries = append(summaries, summary)
+
+			notifyProgress(input, Progress{Stage: stage, Level: level, Done: idx + 1, Total: len(chunks)})
+		}
+
+		lines = summaries
+		stage = StageReduce
+	}
+}
+
+func notifyProgress(input *SummarizeInput, progress Progress) {
+	if input.OnProgress != nil {
+		input.OnProgress(progress)
+	}
+}
+
+func newMessages(prompt string, lines []string) []llm_supplier.Message {
+	return []llm_supplier.Message{
+		{Role: llm_supplier.RoleSystem, Content: prompt},
+		{Role: llm_supplier.RoleUser, Content: strings.Join(lines, "\n")},
+	}
+}
+
+func (r *Service) complete(ctx context.Context, prompt string, lines []string) (string, error) {
+	resp, err := r.llm.OneMessage(ctx, newMessages(prompt, lines))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to complete")
+	}
+
+	return resp, nil
+}
+
+// completeFinal
+// Completes answer, which is shown to user, it is streamed if onPartial is passed.
+func (r *Service) completeFinal(
+	ctx context.Context,
+	onPartial func(answer string),
+	messages []llm_supplier.Message,
+) (string, error) {
+	if onPartial == nil {
+		resp, err := r.llm.OneMessage(ctx, messages)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to complete")
+		}
+
+		return resp, nil
+	}
+
+	var partial strings.Builder
+
+	resp, err := r.llm.StreamMessage(ctx, messages, func(delta string) error {
+		partial.WriteString(delta)
+		onPartial(partial.String())
+
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to stream completion")
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,122 @@
+package summarize_service
+
+import (
+	"context"
+	"fun_telegram/core/supplier/llm_supplier"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type llmMock struct {
+	requests [][]llm_supplier.Message
+}
+
+func (r *llmMock) OneMessage(_ context.Context, messages []llm_supplier.Message) (string, error) {
+	r.requests = append(r.requests, messages)
+
+	return "summary", nil
+}
+
+func (r *llmMock) StreamMessage(
+	ctx context.Context,
+	messages []llm_supplier.Message,
+	onDelta func(delta string) error,
+) (string, error) {
+	resp, err := r.OneMessage(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+
+	for _, delta := range []string{"sum", "mary"} {
+		err = onDelta(delta)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return resp, nil
+}
+
+func TestUnit_SummarizeService_ChunkLines_Ok(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{
+		strings.Repeat("a", 30),
+		strings.Repeat("b", 30),
+		strings.Repeat("c", 30),
+		strings.Repeat("d", 300),
+	}
+
+	chunks := chunkLines(lines, 25)
+
+	require.Len(t, chunks, 3)
+	assert.Equal(t, lines[:2], chunks[0])
+	assert.Equal(t, lines[2:3], chunks[1])
+	assert.LessOrEqual(t, EstimateTokens(chunks[2][0]), 25)
+}
+
+func TestUnit_SummarizeService_SummarizeOneChunk_Ok(t *testing.T) {
+	t.Parallel()
+
+	llm := &llmMock{}
+	service, err := New(llm, 1000)
+	require.NoError(t, err)
+
+	partials := make([]string, 0, 2)
+
+	summary, err := service.Summarize(t.Context(), &SummarizeInput{
+		Prompt:    "prompt",
+		Lines:     []string{"a", "b"},
+		OnPartial: func(summary string) { partials = append(partials, summary) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sum", "summary"}, partials)
+
+	assert.Equal(t, "summary", summary)
+	require.Len(t, llm.requests, 1)
+	assert.Equal(t, "prompt", llm.requests[0][0].Content)
+	assert.Equal(t, "a\nb", llm.requests[0][1].Content)
+}
+
+func TestUnit_SummarizeService_SummarizeMapReduce_Ok(t *testing.T) {
+	t.Parallel()
+
+	llm := &llmMock{}
+	service, err := New(llm, 1000)
+	require.NoError(t, err)
+
+	lines := make([]string, 0, 100)
+	for range 100 {
+		lines = append(lines, strings.Repeat("a", 100))
+	}
+
+	progress := make([]Progress, 0)
+
+	summary, err := service.Summarize(t.Context(), &SummarizeInput{
+		Prompt:     "prompt",
+		Lines:      lines,
+		OnProgress: func(p Progress) { progress = append(progress, p) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "summary", summary)
+	require.Greater(t, len(llm.requests), 2)
+
+	last := progress[len(progress)-1]
+	assert.Equal(t, Progress{Stage: StageReduce, Level: 1, Done: 1, Total: 1}, last)
+	assert.Equal(t, StageMap, progress[0].Stage)
+	assert.Len(t, progress, len(llm.requests))
+}
+
+func TestUnit_SummarizeService_CitedSources_Ok(t *testing.T) {
+	t.Parallel()
+
+	answer := "Релиз перенесли на пятницу [3, 1]. Дату обсуждали ещё раз [1][7] и [2 ,3]."
+
+	assert.Equal(t, []int{3, 1, 2}, CitedSources(answer, 5))
+	assert.Empty(t, CitedSources("Ответа нет", 5))
+}
@@ -15,6 +15,11 @@ type telegram struct {
 	RateLimiterEnabled bool          `env:"RATE_LIMITER_ENABLED" envDefault:"true"`
 	RateLimiterRate    time.Duration `env:"RATE_LIMITER_RATE"    envDefault:"100ms"`
 	RateLimiterLimit   int           `env:"RATE_LIMITER_LIMIT"   envDefault:"100"`
+
+	// CaptureChatIDs are chats, which new messages are stored as they arrive.
+	CaptureChatIDs []int64 `env:"CAPTURE_CHAT_IDS"`
+	// UploadConcurrency is max amount of history uploads running at once, uploads of one chat are never parallel.
+	UploadConcurrency int `env:"UPLOAD_CONCURRENCY" envDefault:"2"`
 }
 
 type gigachat struct {
@@ -23,11 +28,36 @@ type gigachat struct {
 	AuthorizationKey string `env:"AUTHORIZATION_KEY" envDefault:""`
 }
 
+// openAI
+// Settings of OpenAI-compatible api, defaults are for local ollama.
+type openAI struct {
+	BaseURL string        `env:"BASE_URL" envDefault:"http://localhost:11434/v1"`
+	APIKey  string        `env:"API_KEY"  envDefault:""`
+	Model   string        `env:"MODEL"    envDefault:"llama3.1"`
+	Timeout time.Duration `env:"TIMEOUT"  envDefault:"5m"`
+}
+
 type Settings struct {
 	Telegram telegram `envPrefix:"TELEGRAM__"`
 	Gigachat gigachat `envPrefix:"GIGACHAT__"`
+	OpenAI   openAI   `envPrefix:"OPENAI__"`
+
+	// LLMProvider can be gigachat or openai, openai is any OpenAI-compatible api, e.g. ollama or llama.cpp.
+	LLMProvider string `env:"LLM_PROVIDER" envDefault:"gigachat"`
 
 	DsSupplierURL string `env:"DS_SUPPLIER_URL" envDefault:"http://0.0.0.0:8000"`
+	// ChartBackend can be ds or native, ds falls back to native if ds supplier is unavailable.
+	ChartBackend string `env:"CHART_BACKEND" envDefault:"ds"`
+	DBPath       string `env:"DB_PATH"       envDefault:".data/fun.db"`
+	// ToxicityScorer can be regex, lexicon or ds, regex is used if ds supplier fails.
+	ToxicityScorer string `env:"TOXICITY_SCORER" envDefault:"regex"`
+	// SummarizeTokenBudget is max amount of tokens in one request to llm, longer chats are summarized by chunks.
+	SummarizeTokenBudget int `env:"SUMMARIZE_TOKEN_BUDGET" envDefault:"6000"`
+	// AnonymizeSalt makes aliases of anonymized users impossible to match by their ids.
+	// If it is empty, random salt is generated on first use and stored in database.
+	AnonymizeSalt string `env:"ANONYMIZE_SALT"`
+	// ShutdownTimeout is time given to running commands to finish on shutdown, then they are cancelled.
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"1m"`
 }
 
 var AppSettings = settings_utils.MustGetSetting[Settings]("FUN_") //nolint: gochecknoglobals // FIXME
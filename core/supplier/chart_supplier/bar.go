@@ -0,0 +1,93 @@
+package chart_supplier
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+	"image"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type barValue struct {
+	label string
+	value float64
+}
+
+// DrawBar
+// Draws horizontal bar chart, bars are sorted by value.
+func (r *Supplier) DrawBar(_ context.Context, input *ds_supplier.DrawBarInput) ([]byte, error) {
+	c, err := r.newCanvas(&input.DrawInput)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	values := make([]barValue, 0, len(input.Values))
+	for label, value := range input.Values {
+		values = append(values, barValue{label: label, value: value})
+	}
+
+	slices.SortFunc(values, func(a, b barValue) int {
+		if a.value == b.value {
+			return strings.Compare(a.label, b.label)
+		}
+
+		if (a.value < b.value) == input.Asc {
+			return -1
+		}
+
+		return 1
+	})
+
+	if input.Limit > 0 && input.Limit < len(values) {
+		values = values[:input.Limit]
+	}
+
+	if len(values) == 0 {
+		return nil, errors.New("no values to draw")
+	}
+
+	var maxValue, labelWidth float64
+	for _, value := range values {
+		maxValue = max(maxValue, value.value)
+		labelWidth = max(labelWidth, float64(textWidth(c.label, value.label)))
+	}
+
+	labelWidth = min(labelWidth, float64(c.width())*0.35)
+	maxValue = niceMax(maxValue)
+
+	var (
+		left   = int(labelWidth) + 30
+		right  = c.width() - 80
+		top    = titleSize + 60
+		bottom = c.height() - 60
+		rowH   = float64(bottom-top) / float64(len(values))
+	)
+
+	c.text(left-10, top-10, input.XLabel, c.label, colorAxis, alignRight)
+	c.text((left+right)/2, c.height()-20, input.YLabel, c.label, colorAxis, alignCenter)
+
+	const ticks = 5
+	for tick := range ticks + 1 {
+		x := left + (right-left)*tick/ticks
+		c.line(x, top, x, bottom, 1, colorGrid)
+		c.text(x, bottom+20, formatValue(maxValue*float64(tick)/ticks), c.small, colorAxis, alignCenter)
+	}
+
+	for idx, value := range values {
+		y0 := top + int(rowH*float64(idx)+rowH*0.15)
+		y1 := top + int(rowH*float64(idx+1)-rowH*0.15)
+		x1 := left + int(float64(right-left)*value.value/maxValue)
+
+		c.rect(image.Rect(left, y0, x1, max(y1, y0+1)), palette[0])
+
+		textY := (y0+y1)/2 + textHeight(c.small)/2
+		c.text(left-10, textY, truncateText(c.label, value.label, int(labelWidth)), c.small, colorText, alignRight)
+		c.text(x1+6, textY, formatValue(value.value), c.small, colorText, alignLeft)
+	}
+
+	c.line(left, top, left, bottom, 2, colorAxis)
+
+	return c.encode()
+}
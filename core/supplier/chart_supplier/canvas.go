@@ -0,0 +1,266 @@
+package chart_supplier
+
+import (
+	"bytes"
+	"fun_telegram/core/supplier/ds_supplier"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	defaultWidth  = 1200
+	defaultHeight = 800
+	// figSizeDPI converts ds supplier figure size in inches to pixels.
+	figSizeDPI  = 100
+	titleSize   = 26
+	jpegQuality = 90
+)
+
+// nolint: gochecknoglobals // palette is constant
+var (
+	colorBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	colorText       = color.RGBA{R: 33, G: 33, B: 33, A: 255}
+	colorAxis       = color.RGBA{R: 120, G: 120, B: 120, A: 255}
+	colorGrid       = color.RGBA{R: 225, G: 225, B: 225, A: 255}
+	palette         = []color.RGBA{
+		{R: 31, G: 119, B: 180, A: 255},
+		{R: 255, G: 127, B: 14, A: 255},
+		{R: 44, G: 160, B: 44, A: 255},
+		{R: 214, G: 39, B: 40, A: 255},
+		{R: 148, G: 103, B: 189, A: 255},
+		{R: 140, G: 86, B: 75, A: 255},
+		{R: 227, G: 119, B: 194, A: 255},
+		{R: 127, G: 127, B: 127, A: 255},
+		{R: 188, G: 189, B: 34, A: 255},
+		{R: 23, G: 190, B: 207, A: 255},
+	}
+)
+
+type canvas struct {
+	img *image.RGBA
+
+	title font.Face
+	label font.Face
+	small font.Face
+
+	format string
+}
+
+func (r *Supplier) newCanvas(input *ds_supplier.DrawInput) (*canvas, error) {
+	width, height := defaultWidth, defaultHeight
+	if len(input.FigSize) == 2 && input.FigSize[0] > 0 && input.FigSize[1] > 0 {
+		width, height = input.FigSize[0]*figSizeDPI, input.FigSize[1]*figSizeDPI
+	}
+
+	labelSize := input.LabelFontSize
+	if labelSize == 0 {
+		labelSize = 14
+	}
+
+	c := canvas{
+		img:    image.NewRGBA(image.Rect(0, 0, width, height)),
+		format: input.ImageFormat,
+	}
+
+	var err error
+
+	c.title, err = opentype.NewFace(r.bold, &opentype.FaceOptions{Size: titleSize, DPI: 72})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create title face")
+	}
+
+	c.label, err = opentype.NewFace(r.regular, &opentype.FaceOptions{Size: float64(labelSize), DPI: 72})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create label face")
+	}
+
+	c.small, err = opentype.NewFace(r.regular, &opentype.FaceOptions{Size: float64(labelSize) * 0.8, DPI: 72})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create small face")
+	}
+
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(colorBackground), image.Point{}, draw.Src)
+
+	c.text(width/2, titleSize+16, input.Title, c.title, colorText, alignCenter)
+
+	return &c, nil
+}
+
+func (c *canvas) width() int {
+	return c.img.Bounds().Dx()
+}
+
+func (c *canvas) height() int {
+	return c.img.Bounds().Dy()
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+func textHeight(face font.Face) int {
+	return face.Metrics().Ascent.Ceil()
+}
+
+// truncateText
+// Cuts text to fit into maxWidth pixels.
+func truncateText(face font.Face, s string, maxWidth int) string {
+	if textWidth(face, s) <= maxWidth {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(face, string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
+
+// text
+// Draws text, y is a baseline.
+func (c *canvas) text(x, y int, s string, face font.Face, col color.Color, textAlign align) {
+	switch textAlign {
+	case alignCenter:
+		x -= textWidth(face, s) / 2
+	case alignRight:
+		x -= textWidth(face, s)
+	case alignLeft:
+	}
+
+	drawer := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(s)
+}
+
+// textVertical
+// Draws text rotated by 90 degrees counterclockwise, text starts at y and is placed left of x.
+func (c *canvas) textVertical(x, y int, s string, face font.Face, col color.Color) {
+	width, height := textWidth(face, s), face.Metrics().Height.Ceil()
+	tmp := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	drawer := font.Drawer{
+		Dst:  tmp,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(0, textHeight(face)),
+	}
+	drawer.DrawString(s)
+
+	for tx := range width {
+		for ty := range height {
+			pixel := tmp.RGBAAt(tx, ty)
+			if pixel.A == 0 {
+				continue
+			}
+
+			dst := image.Rect(x+ty-height, y-tx, x+ty-height+1, y-tx+1)
+			draw.Draw(c.img, dst, image.NewUniform(pixel), image.Point{}, draw.Over)
+		}
+	}
+}
+
+func (c *canvas) rect(rect image.Rectangle, col color.Color) {
+	draw.Draw(c.img, rect, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+func (c *canvas) line(x0, y0, x1, y1 int, width float64, col color.Color) {
+	var (
+		dx    = float64(x1 - x0)
+		dy    = float64(y1 - y0)
+		steps = int(math.Max(math.Abs(dx), math.Abs(dy)))
+		half  = int(width / 2)
+	)
+
+	for step := 0; step <= steps; step++ {
+		t := 0.0
+		if steps != 0 {
+			t = float64(step) / float64(steps)
+		}
+
+		x, y := x0+int(math.Round(dx*t)), y0+int(math.Round(dy*t))
+		c.rect(image.Rect(x-half, y-half, x+half+1, y+half+1), col)
+	}
+}
+
+func (c *canvas) circle(cx, cy, radius int, col color.Color) {
+	for y := -radius; y <= radius; y++ {
+		halfWidth := int(math.Sqrt(float64(radius*radius - y*y)))
+		c.rect(image.Rect(cx-halfWidth, cy+y, cx+halfWidth+1, cy+y+1), col)
+	}
+}
+
+func (c *canvas) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if c.format == "png" {
+		err = png.Encode(&buf, c.img)
+	} else {
+		err = jpeg.Encode(&buf, c.img, &jpeg.Options{Quality: jpegQuality})
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode image")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// niceMax
+// Rounds maximum of axis up to 1, 2 or 5 multiplied by power of 10.
+func niceMax(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+
+	return 10 * magnitude
+}
+
+// withAlpha
+// Returns premultiplied color with given alpha.
+func withAlpha(col color.RGBA, alpha uint8) color.RGBA {
+	return color.RGBA{
+		R: uint8(uint16(col.R) * uint16(alpha) / 255),
+		G: uint8(uint16(col.G) * uint16(alpha) / 255),
+		B: uint8(uint16(col.B) * uint16(alpha) / 255),
+		A: alpha,
+	}
+}
@@ -0,0 +1,200 @@
+package chart_supplier
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// nodeNames
+// Returns names of nodes in order of their first appearance in edges.
+func nodeNames(input *ds_supplier.DrawGraphInput) ([]string, []string) {
+	var firsts, seconds []string
+
+	for _, edge := range input.Edges {
+		if !slices.Contains(firsts, edge.First) {
+			firsts = append(firsts, edge.First)
+		}
+
+		if !slices.Contains(seconds, edge.Second) {
+			seconds = append(seconds, edge.Second)
+		}
+	}
+
+	return firsts, seconds
+}
+
+// DrawGraph
+// Draws nodes on a circle connected by edges, RootNode is placed in the center.
+func (r *Supplier) DrawGraph(_ context.Context, input *ds_supplier.DrawGraphInput) ([]byte, error) {
+	c, err := r.newCanvas(&input.DrawInput)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(input.Edges) == 0 {
+		return nil, errors.New("no edges to draw")
+	}
+
+	firsts, seconds := nodeNames(input)
+
+	names := firsts
+	for _, name := range seconds {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var (
+		centerX   = c.width() / 2
+		centerY   = (c.height() + titleSize + 40) / 2
+		radius    = float64(min(c.width(), c.height()-titleSize-40))/2 - 90
+		positions = make(map[string]image.Point, len(names))
+		onCircle  = slices.DeleteFunc(slices.Clone(names), func(name string) bool { return name == input.RootNode })
+	)
+
+	for idx, name := range onCircle {
+		angle := 2*math.Pi*float64(idx)/float64(len(onCircle)) - math.Pi/2
+		positions[name] = image.Pt(
+			centerX+int(radius*math.Cos(angle)),
+			centerY+int(radius*math.Sin(angle)),
+		)
+	}
+
+	if input.RootNode != "" {
+		positions[input.RootNode] = image.Pt(centerX, centerY)
+	}
+
+	var maxWeight float64
+	for _, edge := range input.Edges {
+		maxWeight = max(maxWeight, edge.Weight)
+	}
+
+	for _, edge := range input.Edges {
+		width := 2.0
+		if input.WeightedEdges && maxWeight > 0 {
+			width = 1 + 9*edge.Weight/maxWeight
+		}
+
+		first, second := positions[edge.First], positions[edge.Second]
+		c.line(first.X, first.Y, second.X, second.Y, width, withAlpha(palette[0], 110))
+	}
+
+	for idx, name := range names {
+		position := positions[name]
+		nodeRadius := 10
+
+		if node, ok := input.Nodes[name]; ok && node.Weight > 0 {
+			nodeRadius = int(10 + 10*math.Min(node.Weight, 1))
+		}
+
+		c.circle(position.X, position.Y, nodeRadius, palette[(idx+1)%len(palette)])
+
+		textAlign := alignLeft
+		textX := position.X + nodeRadius + 4
+
+		if position.X < centerX {
+			textAlign = alignRight
+			textX = position.X - nodeRadius - 4
+		}
+
+		c.text(textX, position.Y+textHeight(c.label)/2, name, c.label, colorText, textAlign)
+	}
+
+	return c.encode()
+}
+
+// DrawGraphAsHeatpmap
+// Draws edges as a matrix, rows are first nodes of edges, columns are second ones.
+func (r *Supplier) DrawGraphAsHeatpmap( //nolint: funlen // drawing is long
+	_ context.Context,
+	input *ds_supplier.DrawGraphInput,
+) ([]byte, error) {
+	c, err := r.newCanvas(&input.DrawInput)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(input.Edges) == 0 {
+		return nil, errors.New("no edges to draw")
+	}
+
+	rows, columns := nodeNames(input)
+	weights := make(map[[2]string]float64, len(input.Edges))
+
+	var maxWeight, labelWidth float64
+
+	for _, edge := range input.Edges {
+		weights[[2]string{edge.First, edge.Second}] += edge.Weight
+		maxWeight = max(maxWeight, weights[[2]string{edge.First, edge.Second}])
+	}
+
+	for _, name := range append(slices.Clone(rows), columns...) {
+		labelWidth = max(labelWidth, float64(textWidth(c.small, name)))
+	}
+
+	labelWidth = min(labelWidth, float64(c.height())*0.25)
+
+	var (
+		left   = int(labelWidth) + 50
+		right  = c.width() - 30
+		top    = titleSize + 50
+		bottom = c.height() - int(labelWidth) - 50
+		cellW  = float64(right-left) / float64(len(columns))
+		cellH  = float64(bottom-top) / float64(len(rows))
+	)
+
+	for rowIdx, row := range rows {
+		y0 := top + int(cellH*float64(rowIdx))
+		y1 := top + int(cellH*float64(rowIdx+1))
+
+		c.text(left-8, (y0+y1)/2+textHeight(c.small)/2,
+			truncateText(c.small, row, int(labelWidth)), c.small, colorText, alignRight)
+
+		for columnIdx, column := range columns {
+			x0 := left + int(cellW*float64(columnIdx))
+			x1 := left + int(cellW*float64(columnIdx+1))
+
+			weight := weights[[2]string{row, column}]
+			c.rect(image.Rect(x0, y0, x1, y1), heatColor(weight/maxWeight))
+
+			if weight > 0 && textWidth(c.small, formatValue(weight)) < int(cellW)-4 && int(cellH) > textHeight(c.small) {
+				textColor := colorText
+				if weight/maxWeight > 0.6 {
+					textColor = colorBackground
+				}
+
+				c.text((x0+x1)/2, (y0+y1)/2+textHeight(c.small)/2, formatValue(weight), c.small, textColor, alignCenter)
+			}
+		}
+	}
+
+	for columnIdx, column := range columns {
+		label := truncateText(c.small, column, int(labelWidth))
+		x := left + int(cellW*(float64(columnIdx)+0.5)) + textHeight(c.small)/2
+		c.textVertical(x, bottom+8+textWidth(c.small, label), label, c.small, colorText)
+	}
+
+	c.text((left+right)/2, c.height()-15, input.XLabel, c.label, colorAxis, alignCenter)
+	c.textVertical(25, (top+bottom)/2+textWidth(c.label, input.YLabel)/2, input.YLabel, c.label, colorAxis)
+
+	return c.encode()
+}
+
+// heatColor
+// Interpolates from white to dark red, share is in [0, 1].
+func heatColor(share float64) color.RGBA {
+	share = math.Max(0, math.Min(share, 1))
+
+	return color.RGBA{
+		R: uint8(255 - 105*share),
+		G: uint8(255 - 235*share),
+		B: uint8(255 - 225*share),
+		A: 255,
+	}
+}
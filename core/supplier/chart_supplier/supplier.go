@@ -0,0 +1,29 @@
+package chart_supplier
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+)
+
+// Supplier
+// Draws charts in pure go, implements the same contract as ds_supplier.Supplier.
+type Supplier struct {
+	regular *opentype.Font
+	bold    *opentype.Font
+}
+
+func New() (*Supplier, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse regular font")
+	}
+
+	bold, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse bold font")
+	}
+
+	return &Supplier{regular: regular, bold: bold}, nil
+}
@@ -0,0 +1,85 @@
+package chart_supplier
+
+import (
+	"bytes"
+	"fun_telegram/core/supplier/ds_supplier"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func getSupplier(t *testing.T) *Supplier {
+	t.Helper()
+
+	r, err := New()
+	require.NoError(t, err)
+
+	return r
+}
+
+func assertJPEG(t *testing.T, content []byte) {
+	t.Helper()
+
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, defaultWidth, img.Bounds().Dx())
+	assert.Equal(t, defaultHeight, img.Bounds().Dy())
+}
+
+func TestUnit_ChartSupplier_DrawBar_Ok(t *testing.T) {
+	t.Parallel()
+
+	content, err := getSupplier(t).DrawBar(test_utils.GetLoggedContext(), &ds_supplier.DrawBarInput{
+		DrawInput: ds_supplier.DrawInput{Title: "Chatter boxes", XLabel: "User", YLabel: "Words written"},
+		Values:    map[string]float64{"Настя (@nastik)": 120, "Петя": 40, "tea": 3.5},
+	})
+	require.NoError(t, err)
+	assertJPEG(t, content)
+}
+
+func TestUnit_ChartSupplier_DrawTimeseries_Ok(t *testing.T) {
+	t.Parallel()
+
+	content, err := getSupplier(t).DrawTimeseries(test_utils.GetLoggedContext(), &ds_supplier.DrawTimeseriesInput{
+		DrawInput: ds_supplier.DrawInput{Title: "Word written by date", XLabel: "Date", YLabel: "Words written"},
+		Values: map[string]map[string]float64{"day": {
+			"2024-01-01T00:00:00Z": 10,
+			"2024-01-08T00:00:00Z": 30,
+			"2024-01-15T00:00:00Z": 20,
+		}},
+	})
+	require.NoError(t, err)
+	assertJPEG(t, content)
+}
+
+func TestUnit_ChartSupplier_DrawGraph_Ok(t *testing.T) {
+	t.Parallel()
+
+	input := ds_supplier.DrawGraphInput{
+		DrawInput:     ds_supplier.DrawInput{Title: "Interlocutors", XLabel: "User replied by", YLabel: "User replies to"},
+		WeightedEdges: true,
+		Edges: []ds_supplier.GraphEdge{
+			{First: "Настя", Second: "Петя", Weight: 10},
+			{First: "Петя", Second: "tea", Weight: 3},
+			{First: "tea", Second: "Настя", Weight: 1},
+		},
+	}
+
+	content, err := getSupplier(t).DrawGraph(test_utils.GetLoggedContext(), &input)
+	require.NoError(t, err)
+	assertJPEG(t, content)
+
+	content, err = getSupplier(t).DrawGraphAsHeatpmap(test_utils.GetLoggedContext(), &input)
+	require.NoError(t, err)
+	assertJPEG(t, content)
+}
+
+func TestUnit_ChartSupplier_DrawBar_NoValues_Err(t *testing.T) {
+	t.Parallel()
+
+	_, err := getSupplier(t).DrawBar(test_utils.GetLoggedContext(), &ds_supplier.DrawBarInput{})
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package chart_supplier
+
+import (
+	"context"
+	"fun_telegram/core/supplier/ds_supplier"
+	"image"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type timePoint struct {
+	at    time.Time
+	value float64
+}
+
+func parseSeries(values map[string]float64) ([]timePoint, error) {
+	points := make([]timePoint, 0, len(values))
+
+	for key, value := range values {
+		at, err := time.Parse(time.RFC3339, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse time: %s", key)
+		}
+
+		points = append(points, timePoint{at: at, value: value})
+	}
+
+	slices.SortFunc(points, func(a, b timePoint) int {
+		return a.at.Compare(b.at)
+	})
+
+	return points, nil
+}
+
+// DrawTimeseries
+// Draws line chart for each series, keys of series are RFC3339 timestamps.
+func (r *Supplier) DrawTimeseries( //nolint: funlen // drawing is long
+	_ context.Context,
+	input *ds_supplier.DrawTimeseriesInput,
+) ([]byte, error) {
+	c, err := r.newCanvas(&input.DrawInput)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	names := slices.Sorted(maps.Keys(input.Values))
+	series := make([][]timePoint, 0, len(names))
+
+	var (
+		minAt, maxAt time.Time
+		maxValue     float64
+	)
+
+	for _, name := range names {
+		points, err := parseSeries(input.Values[name])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, point := range points {
+			if minAt.IsZero() || point.at.Before(minAt) {
+				minAt = point.at
+			}
+
+			if point.at.After(maxAt) {
+				maxAt = point.at
+			}
+
+			maxValue = max(maxValue, point.value)
+		}
+
+		series = append(series, points)
+	}
+
+	if minAt.IsZero() {
+		return nil, errors.New("no values to draw")
+	}
+
+	maxValue = niceMax(maxValue)
+	span := maxAt.Sub(minAt)
+
+	var (
+		left   = 90
+		right  = c.width() - 40
+		top    = titleSize + 60
+		bottom = c.height() - 80
+	)
+
+	xOf := func(at time.Time) int {
+		if span == 0 {
+			return (left + right) / 2
+		}
+
+		return left + int(float64(right-left)*float64(at.Sub(minAt))/float64(span))
+	}
+	yOf := func(value float64) int {
+		return bottom - int(float64(bottom-top)*value/maxValue)
+	}
+
+	const ticks = 5
+	for tick := range ticks + 1 {
+		y := top + (bottom-top)*tick/ticks
+		c.line(left, y, right, y, 1, colorGrid)
+		c.text(left-10, y+5, formatValue(maxValue*float64(ticks-tick)/ticks), c.small, colorAxis, alignRight)
+
+		at := minAt.Add(span * time.Duration(tick) / ticks)
+
+		layout := time.DateOnly
+		if input.OnlyTime {
+			layout = time.TimeOnly
+		}
+
+		c.text(xOf(at), bottom+22, at.Format(layout), c.small, colorAxis, alignCenter)
+	}
+
+	c.line(left, bottom, right, bottom, 2, colorAxis)
+	c.line(left, top, left, bottom, 2, colorAxis)
+	c.text((left+right)/2, c.height()-20, input.XLabel, c.label, colorAxis, alignCenter)
+	c.textVertical(30, (top+bottom)/2+textWidth(c.label, input.YLabel)/2, input.YLabel, c.label, colorAxis)
+
+	for idx, points := range series {
+		col := palette[idx%len(palette)]
+
+		for pointIdx, point := range points {
+			x, y := xOf(point.at), yOf(point.value)
+			if pointIdx > 0 {
+				c.line(xOf(points[pointIdx-1].at), yOf(points[pointIdx-1].value), x, y, 3, col)
+			}
+
+			c.circle(x, y, 3, col)
+		}
+	}
+
+	if len(series) > 1 {
+		for idx, name := range names {
+			y := top + 10 + idx*(textHeight(c.small)+10)
+			c.rect(image.Rect(right-160, y-textHeight(c.small), right-145, y), palette[idx%len(palette)])
+			c.text(right-140, y, truncateText(c.small, name, 140), c.small, colorText, alignLeft)
+		}
+	}
+
+	return c.encode()
+}
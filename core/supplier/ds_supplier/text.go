@@ -0,0 +1,38 @@
+package ds_supplier
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+type ClassifyToxicityInput struct {
+	Texts []string `json:"texts"`
+}
+
+type ClassifyToxicityOutput struct {
+	Scores []float64 `json:"scores"`
+}
+
+// ClassifyToxicity
+// Returns toxicity of each text from 0 to 1.
+func (r *Supplier) ClassifyToxicity(ctx context.Context, texts []string) ([]float64, error) {
+	body, err := r.sendRequest(ctx, "text/toxicity", &ClassifyToxicityInput{Texts: texts})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to classify toxicity")
+	}
+
+	var output ClassifyToxicityOutput
+
+	err = json.Unmarshal(body, &output)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	if len(output.Scores) != len(texts) {
+		return nil, errors.Errorf("wrong amount of scores: %d, expected: %d", len(output.Scores), len(texts))
+	}
+
+	return output.Scores, nil
+}
@@ -16,7 +16,23 @@ import (
 	"github.com/tidwall/gjson"
 )
 
-func (r *Supplier) auth(ctx context.Context) error {
+func (r *Supplier) getAccessToken() string {
+	r.accessTokenMu.Lock()
+	defer r.accessTokenMu.Unlock()
+
+	return r.accessToken
+}
+
+// auth
+// Requests new access token, concurrent requests wait for one authorization.
+func (r *Supplier) auth(ctx context.Context, expiredToken string) error {
+	r.accessTokenMu.Lock()
+	defer r.accessTokenMu.Unlock()
+
+	if r.accessToken != expiredToken {
+		return nil
+	}
+
 	body := strings.NewReader(`scope=GIGACHAT_API_PERS`)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, shared.AppSettings.Gigachat.AuthURL, body)
@@ -34,6 +50,8 @@ func (r *Supplier) auth(ctx context.Context) error {
 		return errors.Wrap(err, "failed to do request")
 	}
 
+	defer closer_utils.CloseOrLog(ctx, resp.Body)
+
 	if resp.StatusCode != http.StatusOK {
 		return errors.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -43,8 +61,6 @@ func (r *Supplier) auth(ctx context.Context) error {
 		return errors.WithStack(err)
 	}
 
-	closer_utils.CloseOrLog(ctx, resp.Body)
-
 	accessToken := gjson.GetBytes(bodyBytes, "access_token").String()
 	if accessToken == "" {
 		return errors.New("access token is empty")
@@ -57,7 +73,7 @@ func (r *Supplier) auth(ctx context.Context) error {
 	return nil
 }
 
-func (r *Supplier) doRequest(ctx context.Context, body any) (*http.Response, error) {
+func (r *Supplier) doRequest(ctx context.Context, accessToken string, body any) (*http.Response, error) {
 	bodyJSON, err := json.Marshal(body)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -75,7 +91,7 @@ func (r *Supplier) doRequest(ctx context.Context, body any) (*http.Response, err
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Bearer "+r.accessToken)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -86,7 +102,17 @@ func (r *Supplier) doRequest(ctx context.Context, body any) (*http.Response, err
 }
 
 func (r *Supplier) sendRequestWithAuth(ctx context.Context, body any) (*http.Response, error) {
-	resp, err := r.doRequest(ctx, body)
+	accessToken := r.getAccessToken()
+	if accessToken == "" {
+		err := r.auth(ctx, accessToken)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to autorize")
+		}
+
+		accessToken = r.getAccessToken()
+	}
+
+	resp, err := r.doRequest(ctx, accessToken, body)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -95,21 +121,25 @@ func (r *Supplier) sendRequestWithAuth(ctx context.Context, body any) (*http.Res
 		return resp, nil
 	}
 
+	closer_utils.CloseOrLog(ctx, resp.Body)
+
 	if resp.StatusCode != http.StatusUnauthorized {
 		return nil, errors.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	err = r.auth(ctx)
+	err = r.auth(ctx, accessToken)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to autorize")
 	}
 
-	resp, err = r.doRequest(ctx, body)
+	resp, err = r.doRequest(ctx, r.getAccessToken(), body)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		closer_utils.CloseOrLog(ctx, resp.Body)
+
 		return nil, errors.Errorf("unexpected status code after reauth: %d", resp.StatusCode)
 	}
 
@@ -4,19 +4,24 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"fun_telegram/core/supplier/llm_supplier"
 	"net/http"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/teadove/teasutils/utils/closer_utils"
 )
 
 type Supplier struct {
-	accessToken string
+	accessToken   string
+	accessTokenMu sync.Mutex
 
 	httpClient *http.Client
 }
 
-func NewSupplier(ctx context.Context) (*Supplier, error) {
+// NewSupplier
+// Creates supplier, authorization is done on first request, so unavailable gigachat does not break startup.
+func NewSupplier(_ context.Context) (*Supplier, error) {
 	r := &Supplier{
 		httpClient: &http.Client{
 			Transport: &http.Transport{
@@ -25,18 +30,10 @@ func NewSupplier(ctx context.Context) (*Supplier, error) {
 		},
 	}
 
-	err := r.auth(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to auth")
-	}
-
 	return r, nil
 }
 
-type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
+type Message = llm_supplier.Message
 
 type Completion struct {
 	Model             string    `json:"model"`
@@ -76,3 +73,25 @@ func (r *Supplier) OneMessage(ctx context.Context, messages []Message) (string,
 
 	return response.Choices[0].Message.Content, nil
 }
+
+func (r *Supplier) StreamMessage(
+	ctx context.Context,
+	messages []Message,
+	onDelta func(delta string) error,
+) (string, error) {
+	body := Completion{Model: "GigaChat", Messages: messages, Stream: true, RepetitionPenalty: 1}
+
+	resp, err := r.sendRequestWithAuth(ctx, body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to do request")
+	}
+
+	defer closer_utils.CloseOrLog(ctx, resp.Body)
+
+	answer, err := llm_supplier.ReadStream(resp.Body, onDelta)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read stream")
+	}
+
+	return answer, nil
+}
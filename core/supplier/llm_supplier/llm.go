@@ -0,0 +1,23 @@
+package llm_supplier
+
+import "context"
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// LLM
+// Answers to chat of messages, implemented by gigachat_supplier.Supplier and openai_supplier.Supplier.
+type LLM interface {
+	OneMessage(ctx context.Context, messages []Message) (string, error)
+	// StreamMessage
+	// Same as OneMessage, but onDelta is called with each part of answer as soon as it is generated.
+	StreamMessage(ctx context.Context, messages []Message, onDelta func(delta string) error) (string, error)
+}
@@ -0,0 +1,66 @@
+package llm_supplier
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type streamChunk struct {
+	Choices []struct {
+		Delta Message `json:"delta"`
+	} `json:"choices"`
+}
+
+// ReadStream
+// Reads server-sent events of chat completions in OpenAI format, which gigachat uses as well.
+// onDelta is called with each non-empty part of answer, whole answer is returned.
+func ReadStream(reader io.Reader, onDelta func(delta string) error) (string, error) {
+	const maxEventSize = 1024 * 1024
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var answer strings.Builder
+
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return answer.String(), nil
+		}
+
+		var chunk streamChunk
+
+		err := json.Unmarshal([]byte(data), &chunk)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to unmarshal stream chunk")
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		answer.WriteString(delta)
+
+		err = onDelta(delta)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read stream")
+	}
+
+	return answer.String(), nil
+}
@@ -0,0 +1,37 @@
+package llm_supplier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnit_LlmSupplier_ReadStream_Ok(t *testing.T) {
+	t.Parallel()
+
+	stream := `data: {"choices":[{"delta":{"role":"assistant","content":"При"},"index":0}]}
+
+data: {"choices":[{"delta":{"content":""},"index":0}]}
+
+: keep-alive
+data: {"choices":[{"delta":{"content":"вет"},"index":0}]}
+
+data: [DONE]
+
+data: {"choices":[{"delta":{"content":"!"},"index":0}]}
+`
+
+	deltas := make([]string, 0, 2)
+
+	answer, err := ReadStream(strings.NewReader(stream), func(delta string) error {
+		deltas = append(deltas, delta)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Привет", answer)
+	assert.Equal(t, []string{"При", "вет"}, deltas)
+}
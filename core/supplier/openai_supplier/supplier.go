@@ -0,0 +1,139 @@
+package openai_supplier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"fun_telegram/core/supplier/llm_supplier"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/teadove/teasutils/utils/closer_utils"
+)
+
+// Supplier
+// Client of OpenAI-compatible chat completions api, e.g. of ollama or llama.cpp server.
+type Supplier struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+// New
+// Creates supplier, baseURL is url of api without /chat/completions, e.g. http://localhost:11434/v1.
+// Empty apiKey is not sent, as local servers do not require it.
+func New(baseURL string, apiKey string, model string, timeout time.Duration) *Supplier {
+	return &Supplier{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+	}
+}
+
+type completionRequest struct {
+	Model    string                 `json:"model"`
+	Messages []llm_supplier.Message `json:"messages"`
+	Stream   bool                   `json:"stream"`
+}
+
+type completionResponse struct {
+	Choices []struct {
+		Message llm_supplier.Message `json:"message"`
+	} `json:"choices"`
+}
+
+// sendRequest
+// Sends completion request, response is returned only if status is ok.
+func (r *Supplier) sendRequest(
+	ctx context.Context,
+	messages []llm_supplier.Message,
+	stream bool,
+) (*http.Response, error) {
+	reqBody, err := json.Marshal(&completionRequest{Model: r.model, Messages: messages, Stream: stream})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal request body")
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/chat/completions", r.baseURL),
+		bytes.NewReader(reqBody),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to do request")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		closer_utils.CloseOrLog(ctx, resp.Body)
+
+		return nil, errors.Errorf("wrong status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
+
+func (r *Supplier) OneMessage(ctx context.Context, messages []llm_supplier.Message) (string, error) {
+	resp, err := r.sendRequest(ctx, messages, false)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	defer closer_utils.CloseOrLog(ctx, resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read response body")
+	}
+
+	var response completionResponse
+
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	if len(response.Choices) == 0 {
+		return "", errors.New("no choices found")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
+
+func (r *Supplier) StreamMessage(
+	ctx context.Context,
+	messages []llm_supplier.Message,
+	onDelta func(delta string) error,
+) (string, error) {
+	resp, err := r.sendRequest(ctx, messages, true)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	defer closer_utils.CloseOrLog(ctx, resp.Body)
+
+	answer, err := llm_supplier.ReadStream(resp.Body, onDelta)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read stream")
+	}
+
+	return answer, nil
+}
@@ -0,0 +1,98 @@
+package openai_supplier
+
+import (
+	"encoding/json"
+	"fun_telegram/core/supplier/llm_supplier"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/teadove/teasutils/utils/test_utils"
+)
+
+func TestUnit_OpenaiSupplier_OneMessage_Ok(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", req.URL.Path)
+		assert.Equal(t, "Bearer key", req.Header.Get("Authorization"))
+
+		var body completionRequest
+
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.Equal(t, "llama", body.Model)
+		assert.Len(t, body.Messages, 2)
+
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Привет"}}]}`))
+	}))
+	defer server.Close()
+
+	r := New(server.URL+"/v1/", "key", "llama", time.Minute)
+
+	resp, err := r.OneMessage(test_utils.GetLoggedContext(), []llm_supplier.Message{
+		{Role: llm_supplier.RoleSystem, Content: "Ты - бот"},
+		{Role: llm_supplier.RoleUser, Content: "Привет, как дела?"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Привет", resp)
+}
+
+func TestUnit_OpenaiSupplier_OneMessageWrongStatus_Err(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get("Authorization"))
+
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "model not found"}`))
+	}))
+	defer server.Close()
+
+	r := New(server.URL, "", "llama", time.Minute)
+
+	_, err := r.OneMessage(test_utils.GetLoggedContext(), []llm_supplier.Message{
+		{Role: llm_supplier.RoleUser, Content: "Привет"},
+	})
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "model not found")
+}
+
+func TestUnit_OpenaiSupplier_StreamMessage_Ok(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body completionRequest
+
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		assert.True(t, body.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"При\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"вет\"}}]}\n\n"))
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	r := New(server.URL, "", "llama", time.Minute)
+
+	deltas := make([]string, 0, 2)
+
+	resp, err := r.StreamMessage(
+		test_utils.GetLoggedContext(),
+		[]llm_supplier.Message{{Role: llm_supplier.RoleUser, Content: "Привет"}},
+		func(delta string) error {
+			deltas = append(deltas, delta)
+
+			return nil
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Привет", resp)
+	assert.Equal(t, []string{"При", "вет"}, deltas)
+}
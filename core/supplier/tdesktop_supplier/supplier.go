@@ -0,0 +1,267 @@
+package tdesktop_supplier
+
+import (
+	"encoding/json"
+	"fun_telegram/core/service/message_service"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/pkg/errors"
+)
+
+// Chat
+// Header of Telegram Desktop export.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// exportMessage
+// Message of Telegram Desktop export, only used fields are described.
+type exportMessage struct {
+	ID               int             `json:"id"`
+	Type             string          `json:"type"`
+	DateUnixtime     string          `json:"date_unixtime"`
+	Date             string          `json:"date"`
+	From             string          `json:"from"`
+	FromID           string          `json:"from_id"`
+	ReplyToMessageID int64           `json:"reply_to_message_id"`
+	Text             json.RawMessage `json:"text"`
+}
+
+// Batch
+// Messages and their authors, which are read from export.
+type Batch struct {
+	Chat     Chat
+	Messages message_service.Messages
+	Users    message_service.UsersInChat
+}
+
+// Read
+// Streams result.json of Telegram Desktop export, onBatch is called for every batchSize messages.
+// Service messages and messages not from users are skipped.
+func Read(reader io.Reader, batchSize int, onBatch func(batch *Batch) error) error { //nolint: gocognit // parsing
+	decoder := json.NewDecoder(reader)
+
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var chat Chat
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return errors.Wrap(err, "failed to read key")
+		}
+
+		key, _ := token.(string)
+
+		switch key {
+		case "id":
+			err = decoder.Decode(&chat.ID)
+		case "name":
+			err = decoder.Decode(&chat.Name)
+		case "type":
+			err = decoder.Decode(&chat.Type)
+		case "messages":
+			if chat.ID == 0 {
+				return errors.New("chat id must go before messages")
+			}
+
+			err = readMessages(decoder, &chat, batchSize, onBatch)
+		default:
+			var skip json.RawMessage
+			err = decoder.Decode(&skip)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", key)
+		}
+	}
+
+	return nil
+}
+
+func readMessages(decoder *json.Decoder, chat *Chat, batchSize int, onBatch func(batch *Batch) error) error {
+	err := expectDelim(decoder, '[')
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	batch := newBatch(chat, batchSize)
+	users := make(map[int64]struct{}, 100)
+
+	for decoder.More() {
+		var raw exportMessage
+
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode message")
+		}
+
+		message, ok, err := toMessage(chat.ID, &raw)
+		if err != nil {
+			return errors.Wrapf(err, "failed to convert message: %d", raw.ID)
+		}
+
+		if !ok {
+			continue
+		}
+
+		batch.Messages = append(batch.Messages, message)
+
+		if _, ok = users[message.TgUserID]; !ok {
+			users[message.TgUserID] = struct{}{}
+			batch.Users = append(batch.Users, message_service.UserInChat{
+				TgChatID: chat.ID,
+				TgID:     message.TgUserID,
+				TgName:   raw.From,
+				Status:   message_service.Unknown,
+			})
+		}
+
+		if len(batch.Messages) >= batchSize {
+			err = onBatch(batch)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			batch = newBatch(chat, batchSize)
+		}
+	}
+
+	if len(batch.Messages) != 0 {
+		err = onBatch(batch)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	_, err = decoder.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to read end of messages")
+	}
+
+	return nil
+}
+
+func newBatch(chat *Chat, batchSize int) *Batch {
+	return &Batch{Chat: *chat, Messages: make(message_service.Messages, 0, batchSize)}
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to read token")
+	}
+
+	if token != delim {
+		return errors.Errorf("expected %s, got %v", delim, token)
+	}
+
+	return nil
+}
+
+func toMessage(tgChatID int64, raw *exportMessage) (message_service.Message, bool, error) {
+	userIDString, ok := strings.CutPrefix(raw.FromID, "user")
+	if raw.Type != "message" || !ok {
+		return message_service.Message{}, false, nil
+	}
+
+	userID, err := strconv.ParseInt(userIDString, 10, 64)
+	if err != nil {
+		return message_service.Message{}, false, errors.Wrap(err, "failed to parse user id")
+	}
+
+	createdAt, err := parseDate(raw)
+	if err != nil {
+		return message_service.Message{}, false, errors.WithStack(err)
+	}
+
+	text, err := parseText(raw.Text)
+	if err != nil {
+		return message_service.Message{}, false, errors.WithStack(err)
+	}
+
+	message := message_service.Message{
+		CreatedAt: createdAt,
+		TgChatID:  tgChatID,
+		TgID:      raw.ID,
+		TgUserID:  userID,
+		Text:      text,
+	}
+
+	if raw.ReplyToMessageID != 0 {
+		message.ReplyToTgMsgID = null.IntFrom(raw.ReplyToMessageID)
+	}
+
+	return message, true, nil
+}
+
+// parseDate
+// Uses date_unixtime, as date is written in local time of exporting client without timezone.
+func parseDate(raw *exportMessage) (time.Time, error) {
+	if raw.DateUnixtime != "" {
+		unixtime, err := strconv.ParseInt(raw.DateUnixtime, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "failed to parse date_unixtime")
+		}
+
+		return time.Unix(unixtime, 0).UTC(), nil
+	}
+
+	createdAt, err := time.Parse("2006-01-02T15:04:05", raw.Date)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse date")
+	}
+
+	return createdAt, nil
+}
+
+// parseText
+// Text is either a string, or an array of strings and formatted entities.
+func parseText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []json.RawMessage
+
+	err := json.Unmarshal(raw, &parts)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse text")
+	}
+
+	var builder strings.Builder
+
+	for _, part := range parts {
+		var entity struct {
+			Text string `json:"text"`
+		}
+
+		if err = json.Unmarshal(part, &text); err == nil {
+			builder.WriteString(text)
+			continue
+		}
+
+		err = json.Unmarshal(part, &entity)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to parse text entity")
+		}
+
+		builder.WriteString(entity.Text)
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,66 @@
+package tdesktop_supplier
+
+import (
+	"fun_telegram/core/service/message_service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const export = `{
+ "name": "Fun chat",
+ "type": "private_supergroup",
+ "id": 1234,
+ "messages": [
+  {"id": 1, "type": "service", "date": "2024-01-01T10:00:00", "date_unixtime": "1704092400",
+   "actor": "Peter", "actor_id": "user10", "action": "create_group", "text": ""},
+  {"id": 2, "type": "message", "date": "2024-01-01T10:01:00", "date_unixtime": "1704092460",
+   "from": "Peter", "from_id": "user10", "text": "привет"},
+  {"id": 3, "type": "message", "date": "2024-01-01T10:02:00", "date_unixtime": "1704092520",
+   "from": "Ann", "from_id": "user20", "reply_to_message_id": 2,
+   "text": ["смотри ", {"type": "link", "text": "https://example.com"}, "!"]},
+  {"id": 4, "type": "message", "date": "2024-01-01T10:03:00", "date_unixtime": "1704092580",
+   "from": "News", "from_id": "channel30", "text": "репост"},
+  {"id": 5, "type": "message", "date": "2024-01-01T10:04:00", "date_unixtime": "1704092640",
+   "from": "Peter", "from_id": "user10", "text": "ок"}
+ ]
+}`
+
+func TestUnit_TdesktopSupplier_Read_Ok(t *testing.T) {
+	t.Parallel()
+
+	var batches []*Batch
+
+	err := Read(strings.NewReader(export), 2, func(batch *Batch) error {
+		batches = append(batches, batch)
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+
+	assert.Equal(t, Chat{ID: 1234, Name: "Fun chat", Type: "private_supergroup"}, batches[0].Chat)
+	assert.Equal(t, message_service.Messages{
+		{TgChatID: 1234, TgID: 2, TgUserID: 10, Text: "привет", CreatedAt: time.Unix(1704092460, 0).UTC()},
+		{
+			TgChatID:       1234,
+			TgID:           3,
+			TgUserID:       20,
+			Text:           "смотри https://example.com!",
+			CreatedAt:      time.Unix(1704092520, 0).UTC(),
+			ReplyToTgMsgID: null.IntFrom(2),
+		},
+	}, batches[0].Messages)
+	assert.Equal(t, message_service.UsersInChat{
+		{TgChatID: 1234, TgID: 10, TgName: "Peter", Status: message_service.Unknown},
+		{TgChatID: 1234, TgID: 20, TgName: "Ann", Status: message_service.Unknown},
+	}, batches[0].Users)
+
+	require.Len(t, batches[1].Messages, 1)
+	assert.Equal(t, 5, batches[1].Messages[0].TgID)
+	assert.Empty(t, batches[1].Users)
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"fun_telegram/core/container"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/teadove/teasutils/utils/logger_utils"
 )
@@ -9,12 +12,40 @@ import (
 func main() {
 	ctx := logger_utils.NewLoggedCtx()
 
+	if len(os.Args) > 1 {
+		cliPresentation, err := container.NewCLIContainer(ctx)
+		if err != nil {
+			panic(err)
+		}
+
+		err = cliPresentation.Run(ctx, os.Args[1:])
+		if err != nil {
+			panic(err)
+		}
+
+		err = cliPresentation.Close()
+		if err != nil {
+			panic(err)
+		}
+
+		return
+	}
+
 	combatContainer, err := container.NewContainer(ctx)
 	if err != nil {
 		panic(err)
 	}
 
-	err = combatContainer.Presentation.Run(ctx)
+	// Bot is shut down gracefully on SIGTERM of docker or on ctrl+c
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	err = combatContainer.Presentation.Run(signalCtx)
+	if err != nil {
+		panic(err)
+	}
+
+	err = combatContainer.Presentation.Close()
 	if err != nil {
 		panic(err)
 	}